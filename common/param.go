@@ -0,0 +1,51 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Param returns the path parameter name without the leading '/' gin keeps on
+// catch-all parameters, "/docs/a.txt" of *path is "docs/a.txt". It is the
+// value the route constraints check.
+func Param(ctx *gin.Context, name string) string {
+	return strings.TrimPrefix(ctx.Param(name), "/")
+}
+
+// ParamInt returns the path parameter name as an int, and an error when it is
+// not one. Constrain the parameter with <int> in the route path to reject
+// such requests before the controllers run.
+func ParamInt(ctx *gin.Context, name string) (int, error) {
+	v, err := strconv.Atoi(Param(ctx, name))
+	return v, paramError(name, err)
+}
+
+func ParamInt64(ctx *gin.Context, name string) (int64, error) {
+	v, err := strconv.ParseInt(Param(ctx, name), 10, 64)
+	return v, paramError(name, err)
+}
+
+func ParamUint64(ctx *gin.Context, name string) (uint64, error) {
+	v, err := strconv.ParseUint(Param(ctx, name), 10, 64)
+	return v, paramError(name, err)
+}
+
+func ParamFloat64(ctx *gin.Context, name string) (float64, error) {
+	v, err := strconv.ParseFloat(Param(ctx, name), 64)
+	return v, paramError(name, err)
+}
+
+func ParamBool(ctx *gin.Context, name string) (bool, error) {
+	v, err := strconv.ParseBool(Param(ctx, name))
+	return v, paramError(name, err)
+}
+
+func paramError(name string, err error) error {
+	if err != nil {
+		return fmt.Errorf("path parameter %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParams(t *testing.T) {
+	ctx := &gin.Context{Params: gin.Params{
+		{Key: "id", Value: "42"},
+		{Key: "ratio", Value: "0.5"},
+		{Key: "flag", Value: "true"},
+		{Key: "name", Value: "bob"},
+		{Key: "path", Value: "/docs/a.txt"},
+		{Key: "rest", Value: "/7"},
+	}}
+
+	if v, err := ParamInt(ctx, "id"); err != nil || v != 42 {
+		t.Errorf("ParamInt = %d, %v", v, err)
+	}
+	if v, err := ParamInt64(ctx, "id"); err != nil || v != 42 {
+		t.Errorf("ParamInt64 = %d, %v", v, err)
+	}
+	if v, err := ParamUint64(ctx, "id"); err != nil || v != 42 {
+		t.Errorf("ParamUint64 = %d, %v", v, err)
+	}
+	if v, err := ParamFloat64(ctx, "ratio"); err != nil || v != 0.5 {
+		t.Errorf("ParamFloat64 = %v, %v", v, err)
+	}
+	if v, err := ParamBool(ctx, "flag"); err != nil || !v {
+		t.Errorf("ParamBool = %v, %v", v, err)
+	}
+
+	// catch-all values lose the leading slash
+	if v := Param(ctx, "path"); v != "docs/a.txt" {
+		t.Errorf("Param = %q", v)
+	}
+	if v, err := ParamInt(ctx, "rest"); err != nil || v != 7 {
+		t.Errorf("ParamInt of a catch-all = %d, %v", v, err)
+	}
+
+	for _, name := range []string{"name", "missing"} {
+		if _, err := ParamInt(ctx, name); !errors.Is(err, strconv.ErrSyntax) {
+			t.Errorf("ParamInt(%q): expected a syntax error, got %v", name, err)
+		}
+	}
+	if _, err := ParamUint64(ctx, "name"); err == nil {
+		t.Error("ParamUint64 accepted a name")
+	}
+}
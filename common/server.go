@@ -0,0 +1,18 @@
+package common
+
+import "context"
+
+const serverStoppingKey = ContextKey("server_stopping")
+
+// WithServerStopping returns a copy of ctx carrying the channel closed when the
+// server begins to stop.
+func WithServerStopping(ctx context.Context, stopping <-chan struct{}) context.Context {
+	return context.WithValue(ctx, serverStoppingKey, stopping)
+}
+
+// ServerStopping returns the channel closed when the server begins to stop,
+// nil outside a server. Long running handlers end on it.
+func ServerStopping(ctx context.Context) <-chan struct{} {
+	stopping, _ := ctx.Value(serverStoppingKey).(<-chan struct{})
+	return stopping
+}
@@ -0,0 +1,24 @@
+package common
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetClientCertificate(ctx *gin.Context) *x509.Certificate {
+	state := ctx.Request.TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+func GetClientCertSubject(ctx *gin.Context) (pkix.Name, bool) {
+	cert := GetClientCertificate(ctx)
+	if cert == nil {
+		return pkix.Name{}, false
+	}
+	return cert.Subject, true
+}
@@ -1,6 +1,9 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+
 	"github.com/anyufly/gin_common/apierr"
 	"github.com/go-playground/validator/v10"
 	"net/http"
@@ -32,7 +35,11 @@ func ControllerHandler(controllerFunc ControllerFunc) gin.HandlerFunc {
 			response.EmptyError.WithErr(r).Render(ctx)
 		case error:
 			loggers.LogRequestErr(ctx, r)
-			response.UnknownError.WithErr(r).Render(ctx)
+			if errors.Is(r, context.DeadlineExceeded) {
+				response.GatewayTimeoutError.WithErr(r).Render(ctx)
+			} else {
+				response.UnknownError.WithErr(r).Render(ctx)
+			}
 		default:
 			if data != nil {
 				cr := renders.JSON{Data: data}
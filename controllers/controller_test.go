@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestControllerHandlerErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name   string
+		result interface{}
+		status int
+	}{
+		{name: "data", result: map[string]int{"n": 1}, status: http.StatusOK},
+		{name: "error", result: errors.New("failed"), status: http.StatusInternalServerError},
+		{name: "deadline", result: context.DeadlineExceeded, status: http.StatusGatewayTimeout},
+		{name: "wrapped deadline", result: fmt.Errorf("query: %w", context.DeadlineExceeded), status: http.StatusGatewayTimeout},
+		{name: "canceled", result: context.Canceled, status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.GET("/", ControllerHandler(func(ctx *gin.Context) interface{} {
+				return tt.result
+			}))
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// projectionTags are the other struct tags gin reads when setting a field.
+var projectionTags = []string{"time_format", "time_utc", "time_location", "collection_format"}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type projectionKey struct {
+	typ reflect.Type
+	tag string
+}
+
+// projection holds the fields of a request type carrying a source tag.
+type projection struct {
+	typ     reflect.Type
+	indexes [][]int
+}
+
+var projections sync.Map
+
+func projectionOf(typ reflect.Type, tag string) *projection {
+	key := projectionKey{typ: typ, tag: tag}
+	if p, ok := projections.Load(key); ok {
+		return p.(*projection)
+	}
+
+	p := &projection{}
+	var fields []reflect.StructField
+	collectProjectionFields(typ, tag, nil, func(field reflect.StructField, index []int) {
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("F%d", len(fields)),
+			Type: field.Type,
+			Tag:  projectionTag(field, tag),
+		})
+		p.indexes = append(p.indexes, index)
+	})
+	p.typ = reflect.StructOf(fields)
+
+	actual, _ := projections.LoadOrStore(key, p)
+	return actual.(*projection)
+}
+
+func collectProjectionFields(typ reflect.Type, tag string, parent []int, collect func(reflect.StructField, []int)) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		index := append(append([]int{}, parent...), i)
+
+		value := field.Tag.Get(tag)
+		switch {
+		case value == "-":
+		case value != "" && field.PkgPath == "":
+			collect(field, index)
+		case field.Type.Kind() == reflect.Struct && field.Type != timeType:
+			collectProjectionFields(field.Type, tag, index, collect)
+		}
+	}
+}
+
+func projectionTag(field reflect.StructField, tag string) reflect.StructTag {
+	name, opts, _ := strings.Cut(field.Tag.Get(tag), ",")
+	if name == "" {
+		name = field.Name
+	}
+	if opts != "" {
+		name += "," + opts
+	}
+
+	tags := []string{fmt.Sprintf("%s:%q", tag, name)}
+	for _, key := range projectionTags {
+		if value, ok := field.Tag.Lookup(key); ok {
+			tags = append(tags, fmt.Sprintf("%s:%q", key, value))
+		}
+	}
+	return reflect.StructTag(strings.Join(tags, " "))
+}
+
+// bindProjection binds obj through the projection of its tag fields, non
+// struct objects as is.
+func bindProjection(obj interface{}, tag string, bind func(interface{}) error) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return bind(obj)
+	}
+
+	target := v.Elem()
+	p := projectionOf(target.Type(), tag)
+	projected := reflect.New(p.typ).Elem()
+	for i, index := range p.indexes {
+		projected.Field(i).Set(target.FieldByIndex(index))
+	}
+	if err := bind(projected.Addr().Interface()); err != nil {
+		return err
+	}
+	for i, index := range p.indexes {
+		target.FieldByIndex(index).Set(projected.Field(i))
+	}
+	return nil
+}
+
+func bindUri(params map[string][]string, obj interface{}) error {
+	return bindProjection(obj, "uri", func(ptr interface{}) error {
+		return binding.Uri.BindUri(params, ptr)
+	})
+}
+
+func bindRequest(req *http.Request, obj interface{}, b binding.Binding, tag string) error {
+	return bindProjection(obj, tag, func(ptr interface{}) error {
+		return b.Bind(req, ptr)
+	})
+}
+
+// formTagged reports whether b maps the body with the form tag.
+func formTagged(b binding.Binding) bool {
+	switch b {
+	case binding.Form, binding.FormPost, binding.FormMultipart:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/anyufly/gin_common/renders"
+	"github.com/anyufly/gin_common/response"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+type TypedFunc[Req any, Resp any] func(ctx *gin.Context, req Req) (Resp, error)
+
+// Typed adapts fn to a ControllerFunc binding the request with Bind, the
+// result is wrapped with response.SuccessWithData unless it is a
+// renders.Render.
+func Typed[Req any, Resp any](fn TypedFunc[Req, Resp]) ControllerFunc {
+	return func(ctx *gin.Context) interface{} {
+		var req Req
+		if err := Bind(ctx, &req); err != nil {
+			if ve, ok := err.(validator.ValidationErrors); ok {
+				return ve
+			}
+			response.ParameterError.WithErr(err).Render(ctx)
+			return nil
+		}
+
+		resp, err := fn(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if r, ok := interface{}(resp).(renders.Render); ok {
+			return r
+		}
+		return response.SuccessWithData(resp)
+	}
+}
+
+// Bind fills obj from the path (uri tag), query (form tag), headers (header
+// tag) and body, then validates it. Untagged fields are only set from non
+// form bodies.
+func Bind(ctx *gin.Context, obj interface{}) error {
+	if len(ctx.Params) > 0 {
+		params := make(map[string][]string, len(ctx.Params))
+		for _, param := range ctx.Params {
+			params[param.Key] = []string{param.Value}
+		}
+		if err := ignoreValidation(bindUri(params, obj)); err != nil {
+			return err
+		}
+	}
+	if err := ignoreValidation(bindRequest(ctx.Request, obj, binding.Query, "form")); err != nil {
+		return err
+	}
+	if err := ignoreValidation(bindRequest(ctx.Request, obj, binding.Header, "header")); err != nil {
+		return err
+	}
+	if hasBody(ctx.Request) {
+		b := binding.Default(ctx.Request.Method, ctx.ContentType())
+		var err error
+		if formTagged(b) {
+			err = bindRequest(ctx.Request, obj, b, "form")
+		} else {
+			err = ctx.ShouldBindWith(obj, b)
+		}
+		if err = ignoreValidation(err); err != nil {
+			return err
+		}
+	}
+
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// ignoreValidation drops the validation errors of the partial bindings.
+func ignoreValidation(err error) error {
+	if _, ok := err.(validator.ValidationErrors); ok {
+		return nil
+	}
+	return err
+}
+
+func hasBody(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+		return req.ContentLength > 0
+	default:
+		return req.ContentLength != 0
+	}
+}
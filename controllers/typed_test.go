@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type profileRequest struct {
+	ID    int    `uri:"id"`
+	Name  string `form:"name" json:"name" binding:"required"`
+	Token string `header:"X-Token"`
+	Admin bool
+}
+
+func TestTyped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	errFailed := errors.New("failed")
+	profile := Typed(func(ctx *gin.Context, req profileRequest) (profileRequest, error) {
+		if req.Name == "fail" {
+			return req, errFailed
+		}
+		return req, nil
+	})
+	engine := gin.New()
+	engine.GET("/profiles/:id", ControllerHandler(profile))
+	engine.POST("/profiles/:id", ControllerHandler(profile))
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		header http.Header
+		body   string
+		status int
+		want   profileRequest
+	}{
+		{
+			name:   "tagged fields only",
+			method: http.MethodGet,
+			target: "/profiles/7?name=ann&Admin=true",
+			header: http.Header{"X-Token": {"secret"}, "Admin": {"true"}},
+			status: http.StatusOK,
+			want:   profileRequest{ID: 7, Name: "ann", Token: "secret"},
+		},
+		{
+			name:   "form body",
+			method: http.MethodPost,
+			target: "/profiles/7",
+			header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+			body:   url.Values{"name": {"bob"}, "Admin": {"true"}}.Encode(),
+			status: http.StatusOK,
+			want:   profileRequest{ID: 7, Name: "bob"},
+		},
+		{
+			name:   "json body",
+			method: http.MethodPost,
+			target: "/profiles/7?name=query",
+			header: http.Header{"Content-Type": {"application/json"}},
+			body:   `{"name": "body"}`,
+			status: http.StatusOK,
+			want:   profileRequest{ID: 7, Name: "body"},
+		},
+		{name: "validated once bound", method: http.MethodGet, target: "/profiles/7", status: http.StatusBadRequest},
+		{name: "invalid path parameter", method: http.MethodGet, target: "/profiles/x?name=ann", status: http.StatusBadRequest},
+		{name: "controller error", method: http.MethodGet, target: "/profiles/7?name=fail", status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			for key, values := range tt.header {
+				r.Header[key] = values
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var got struct {
+				Data profileRequest `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Data != tt.want {
+				t.Errorf("bound %+v, want %+v", got.Data, tt.want)
+			}
+		})
+	}
+}
@@ -14,10 +14,12 @@ func LogRequestErr(context *gin.Context, err error) {
 		path := context.Request.URL.Path
 		ip := context.ClientIP()
 		method := context.Request.Method
+		proto := context.Request.Proto
 
 		if e, ok := err.(stackerr.ErrorWithStack); ok {
 			logger.Name("request_error").Error("",
 				"ip", ip,
+				"proto", proto,
 				"method", method,
 				"path", path,
 				"errorSource", fmt.Sprintf("%s:%d", e.File(), e.Line()),
@@ -25,6 +27,7 @@ func LogRequestErr(context *gin.Context, err error) {
 		} else {
 			logger.Name("request_error").Error("",
 				"ip", ip,
+				"proto", proto,
 				"method", method,
 				"path", path,
 				"errMsg", err.Error())
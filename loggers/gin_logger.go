@@ -45,17 +45,29 @@ func (g *ginLogger) Name(name string) GinLogger {
 }
 
 func (g *ginLogger) Info(msg string, keyAndValues ...interface{}) {
+	if !enabled(levelInfo) {
+		return
+	}
 	g.logger.Sugar().Infow(msg, keyAndValues...)
 }
 
 func (g *ginLogger) Debug(msg string, keyAndValues ...interface{}) {
+	if !enabled(levelDebug) {
+		return
+	}
 	g.logger.Sugar().Debugw(msg, keyAndValues...)
 }
 
 func (g *ginLogger) Warn(msg string, keyAndValues ...interface{}) {
+	if !enabled(levelWarn) {
+		return
+	}
 	g.logger.Sugar().Warnw(msg, keyAndValues...)
 }
 
 func (g *ginLogger) Error(msg string, keyAndValues ...interface{}) {
+	if !enabled(levelError) {
+		return
+	}
 	g.logger.Sugar().Errorw(msg, keyAndValues...)
 }
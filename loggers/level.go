@@ -0,0 +1,49 @@
+package loggers
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+const (
+	levelDebug int32 = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+var levelNames = map[string]int32{
+	"debug": levelDebug,
+	"info":  levelInfo,
+	"warn":  levelWarn,
+	"error": levelError,
+}
+
+var errUnknownLevel = errors.New("unknown log level, expect one of debug, info, warn, error")
+
+var currentLevel atomic.Int32
+
+// SetLevel changes at runtime the minimum level written by every GinLogger,
+// messages below the level of the underlying logger stay discarded.
+func SetLevel(level string) error {
+	l, ok := levelNames[level]
+	if !ok {
+		return errUnknownLevel
+	}
+	currentLevel.Store(l)
+	return nil
+}
+
+func Level() string {
+	l := currentLevel.Load()
+	for name, value := range levelNames {
+		if value == l {
+			return name
+		}
+	}
+	return ""
+}
+
+func enabled(level int32) bool {
+	return level >= currentLevel.Load()
+}
@@ -9,6 +9,17 @@ import (
 type LoggerConfig struct {
 	Logger    GinLogger
 	SkipPaths []string
+	Skip      func(c *gin.Context) bool
+}
+
+const accessLogFieldsKey = "_access_log_fields"
+
+// AddAccessLogFields adds key value pairs to the access log entry of the
+// request, written by LoggerWithConfig once the handlers returned.
+func AddAccessLogFields(c *gin.Context, keyAndValues ...interface{}) {
+	fields, _ := c.Get(accessLogFieldsKey)
+	list, _ := fields.([]interface{})
+	c.Set(accessLogFieldsKey, append(list, keyAndValues...))
 }
 
 func Logger(logger GinLogger, notLogged ...string) gin.HandlerFunc {
@@ -38,7 +49,7 @@ func LoggerWithConfig(conf LoggerConfig) gin.HandlerFunc {
 		path := c.Request.URL.Path
 		raw := c.Request.URL.RawQuery
 		c.Next()
-		if _, ok := skip[path]; !ok {
+		if _, ok := skip[path]; !ok && (conf.Skip == nil || !conf.Skip(c)) {
 			param := gin.LogFormatterParams{
 				Request: c.Request,
 				Keys:    c.Keys,
@@ -60,7 +71,7 @@ func LoggerWithConfig(conf LoggerConfig) gin.HandlerFunc {
 
 			param.Path = path
 
-			conf.Logger.Info("",
+			fields := []interface{}{
 				"ip", param.ClientIP,
 				"proto", param.Request.Proto,
 				"method", param.Method,
@@ -68,7 +79,12 @@ func LoggerWithConfig(conf LoggerConfig) gin.HandlerFunc {
 				"status_code", param.StatusCode,
 				"cost", fmt.Sprintf("%d ms", param.Latency.Milliseconds()),
 				"errMsg", param.ErrorMessage,
-				"logType", "request")
+				"logType", "request",
+			}
+			if extra, ok := c.Get(accessLogFieldsKey); ok {
+				fields = append(fields, extra.([]interface{})...)
+			}
+			conf.Logger.Info("", fields...)
 		}
 	}
 }
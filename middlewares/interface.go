@@ -9,4 +9,11 @@ type IMiddleWare interface {
 	AllowAfterAbortContext() bool
 }
 
+// IdentifiedMiddleWare is implemented by middlewares that name their identity:
+// routers treat two middlewares returning the same ID as the same one.
+type IdentifiedMiddleWare interface {
+	IMiddleWare
+	ID() string
+}
+
 type MiddlewareFunc func() IMiddleWare
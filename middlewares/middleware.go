@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"context"
 	"errors"
 	"github.com/anyufly/gin_common/apierr"
 	ginRender "github.com/gin-gonic/gin/render"
@@ -76,7 +77,11 @@ func processMiddlewareFunc(phase int, middleware IMiddleWare, ctx *gin.Context)
 	case error:
 		loggers.LogRequestErr(ctx, r)
 		if allow {
-			response.UnknownError.WithErr(r).Render(ctx)
+			if errors.Is(r, context.DeadlineExceeded) {
+				response.GatewayTimeoutError.WithErr(r).Render(ctx)
+			} else {
+				response.UnknownError.WithErr(r).Render(ctx)
+			}
 			ctx.Abort()
 			return
 		}
@@ -0,0 +1,297 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/anyufly/gin_common/response"
+	"github.com/anyufly/gin_common/routers"
+)
+
+// Generate builds the document of routes, usually the result of
+// routers.Describe.
+func Generate(info Info, routes []routers.RouteInfo) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+	}
+	s := newSchemas()
+
+	for _, route := range routes {
+		p := convertPath(route.Path)
+		item, ok := doc.Paths[p]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[p] = item
+		}
+		setOperation(item, route.Method, operation(s, route))
+	}
+
+	if len(s.named) > 0 {
+		doc.Components.Schemas = s.named
+	}
+	return doc
+}
+
+// convertPath turns the gin path parameters :name and *name into {name}.
+func convertPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		if j := strings.IndexAny(segment, ":*"); j >= 0 {
+			segments[i] = segment[:j] + "{" + segment[j+1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParams(p string) []string {
+	var params []string
+	for _, segment := range strings.Split(p, "/") {
+		if j := strings.IndexAny(segment, ":*"); j >= 0 {
+			params = append(params, segment[j+1:])
+		}
+	}
+	return params
+}
+
+func constraintSchema(constraint routers.ParamConstraint) *Schema {
+	switch constraint.Expr {
+	case "int":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "uint":
+		return &Schema{Type: "integer", Format: "uint64"}
+	case "float":
+		return &Schema{Type: "number", Format: "double"}
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "uuid":
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+	return &Schema{Type: "string", Pattern: constraint.Pattern}
+}
+
+func setOperation(item *PathItem, method string, op *Operation) {
+	switch method {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodDelete:
+		item.Delete = op
+	case http.MethodOptions:
+		item.Options = op
+	case http.MethodHead:
+		item.Head = op
+	case http.MethodPatch:
+		item.Patch = op
+	case http.MethodTrace:
+		item.Trace = op
+	}
+}
+
+func operation(s *schemas, route routers.RouteInfo) *Operation {
+	op := &Operation{Responses: make(map[string]*Response)}
+	doc := route.Desc.Doc
+	if doc == nil {
+		doc = &routers.RouteDoc{}
+	}
+
+	op.Summary = doc.Summary
+	op.Description = doc.Description
+	op.OperationID = doc.OperationID
+	if op.OperationID == "" && route.Desc.Name != "" {
+		op.OperationID = route.Desc.Name
+		if route.Version != "" {
+			op.OperationID = route.Version + "." + route.Desc.Name
+		}
+	}
+	op.Tags = doc.Tags
+	op.Deprecated = route.Desc.Deprecation != nil
+	if len(op.Tags) == 0 {
+		if tag := strings.Trim(route.GroupName, " /"); tag != "" {
+			op.Tags = []string{tag}
+		}
+	}
+
+	declared := make(map[string]bool)
+	if doc.Request != nil {
+		op.Parameters, op.RequestBody = request(s, route.Method, reflect.TypeOf(doc.Request))
+		for _, param := range op.Parameters {
+			if param.In == "path" {
+				declared[param.Name] = true
+				if constraint, ok := route.Constraints[param.Name]; ok && param.Schema.Ref == "" {
+					param.Schema.Pattern = constraint.Pattern
+				}
+			}
+		}
+	}
+	for _, name := range pathParams(route.Path) {
+		if !declared[name] {
+			op.Parameters = append(op.Parameters, &Parameter{
+				Name: name, In: "path", Required: true, Schema: constraintSchema(route.Constraints[name]),
+			})
+		}
+	}
+
+	var data *Schema
+	if doc.Response != nil {
+		data = s.of(reflect.TypeOf(doc.Response))
+	}
+	op.Responses[strconv.Itoa(http.StatusOK)] = &Response{
+		Description: http.StatusText(http.StatusOK),
+		Content:     jsonContent(envelope(&Schema{Type: "string", Const: response.SuccessCode}, data)),
+	}
+
+	errs := doc.Errors
+	if doc.Request != nil || len(route.Constraints) > 0 {
+		errs = append(errs, response.ParameterError)
+	}
+	if opts := route.Desc.Options; opts != nil {
+		if len(opts.Scopes) > 0 {
+			errs = append(errs, response.ForbiddenError)
+		}
+		if opts.MaxBodyBytes > 0 {
+			errs = append(errs, response.RequestEntityTooLargeError)
+		}
+		if opts.RateLimitClass != "" {
+			errs = append(errs, response.TooManyRequestsError)
+		}
+		if opts.Timeout > 0 {
+			errs = append(errs, response.GatewayTimeoutError)
+		}
+	}
+	errs = append(errs, response.UnknownError)
+	for status, resp := range errorResponses(errs) {
+		op.Responses[status] = resp
+	}
+	return op
+}
+
+// request splits the request type the way controllers.Bind fills it: uri,
+// form and header tagged fields are parameters, the others form the body.
+func request(s *schemas, method string, t reflect.Type) ([]*Parameter, *RequestBody) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, jsonBody(s.of(t), true)
+	}
+
+	var params []*Parameter
+	collectParams(s, t, &params)
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return params, nil
+	}
+
+	body := s.object(t, func(field reflect.StructField) bool {
+		return tagName(field, "uri") == "" && tagName(field, "form") == "" && tagName(field, "header") == ""
+	})
+	if len(body.Properties) == 0 {
+		return params, nil
+	}
+	return params, jsonBody(body, len(body.Required) > 0)
+}
+
+func collectParams(s *schemas, t reflect.Type, params *[]*Parameter) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && ft.Kind() == reflect.Struct && ft != timeType {
+			collectParams(s, ft, params)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		for _, in := range [...]struct{ tag, location string }{
+			{"uri", "path"}, {"form", "query"}, {"header", "header"},
+		} {
+			name := tagName(field, in.tag)
+			if name == "" {
+				continue
+			}
+			*params = append(*params, &Parameter{
+				Name:     name,
+				In:       in.location,
+				Required: in.location == "path" || isRequired(field),
+				Schema:   s.of(field.Type),
+			})
+		}
+	}
+}
+
+func jsonBody(schema *Schema, required bool) *RequestBody {
+	return &RequestBody{Required: required, Content: jsonContent(schema)}
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
+
+// envelope describes response.Response wrapping data.
+func envelope(code *Schema, data *Schema) *Schema {
+	schema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code": code,
+			"msg":  {Type: "string"},
+		},
+		Required: []string{"code", "data", "msg"},
+	}
+	if data == nil {
+		data = &Schema{}
+	}
+	schema.Properties["data"] = data
+	return schema
+}
+
+// errorResponses groups the error responses by status code.
+func errorResponses(errs []*response.ErrorResponse) map[string]*Response {
+	byStatus := make(map[int][]*response.ErrorResponse)
+	for _, er := range errs {
+		if er == nil || er.StatusCode() == 0 {
+			continue
+		}
+		byStatus[er.StatusCode()] = append(byStatus[er.StatusCode()], er)
+	}
+
+	responses := make(map[string]*Response, len(byStatus))
+	for status, list := range byStatus {
+		var codes []interface{}
+		var lines []string
+		seen := make(map[string]bool)
+		for _, er := range list {
+			if seen[er.Code] {
+				continue
+			}
+			seen[er.Code] = true
+			codes = append(codes, er.Code)
+			lines = append(lines, fmt.Sprintf("%s: %s", er.Code, er.Msg))
+		}
+		sort.Strings(lines)
+		sort.Slice(codes, func(i, j int) bool { return codes[i].(string) < codes[j].(string) })
+
+		description := http.StatusText(status)
+		if len(lines) > 0 {
+			description += "\n\n" + strings.Join(lines, "\n\n")
+		}
+		responses[strconv.Itoa(status)] = &Response{
+			Description: description,
+			Content:     jsonContent(envelope(&Schema{Type: "string", Enum: codes}, nil)),
+		}
+	}
+	return responses
+}
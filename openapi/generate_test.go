@@ -0,0 +1,170 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anyufly/gin_common/controllers"
+	"github.com/anyufly/gin_common/openapi"
+	"github.com/anyufly/gin_common/response"
+	"github.com/anyufly/gin_common/routers"
+	"github.com/anyufly/gin_common/servertest"
+	"github.com/gin-gonic/gin"
+)
+
+type Page struct {
+	Size int `form:"size"`
+}
+
+type createUser struct {
+	Page
+	OrgID   string `uri:"org"`
+	TraceID string `header:"X-Trace-Id"`
+	Name    string `json:"name" binding:"required" description:"display name"`
+	Email   string `json:"email,omitempty"`
+	Secret  string `json:"-"`
+}
+
+type User struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Tags      []string  `json:"tags"`
+	Manager   *User     `json:"manager,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func ok(ctx *gin.Context) interface{} {
+	return nil
+}
+
+func TestGenerate(t *testing.T) {
+	routes := routers.Describe("/api", servertest.Router{Name: "/users", Routes: map[string][]routers.RouteDesc{
+		"/:org<uuid>": {
+			{
+				Method:     http.MethodPost,
+				Name:       "createUser",
+				Controller: []controllers.ControllerFunc{ok},
+				Doc: &routers.RouteDoc{
+					Summary:  "Create a user",
+					Request:  createUser{},
+					Response: User{},
+					Errors:   []*response.ErrorResponse{response.ForbiddenError},
+				},
+				Options: &routers.RouteOptions{Timeout: time.Second},
+			},
+		},
+		"/:org<uuid>/:id<int>": {
+			{
+				Method:      http.MethodGet,
+				Controller:  []controllers.ControllerFunc{ok},
+				Deprecation: &routers.Deprecation{},
+			},
+		},
+	}})
+	doc := openapi.Generate(openapi.Info{Title: "test", Version: "1"}, routes)
+
+	if doc.OpenAPI != openapi.Version {
+		t.Errorf("openapi %q", doc.OpenAPI)
+	}
+	if len(doc.Paths) != 2 {
+		t.Fatalf("paths %v", doc.Paths)
+	}
+
+	create := doc.Paths["/api/users/{org}"].Post
+	if create == nil {
+		t.Fatalf("no POST /api/users/{org} in %v", doc.Paths)
+	}
+	if create.Summary != "Create a user" || create.OperationID != "createUser" || !reflect.DeepEqual(create.Tags, []string{"users"}) {
+		t.Errorf("operation %+v", create)
+	}
+
+	params := make(map[string]*openapi.Parameter)
+	for _, param := range create.Parameters {
+		params[param.In+" "+param.Name] = param
+	}
+	if len(params) != 3 {
+		t.Errorf("parameters %v", params)
+	}
+	if p := params["path org"]; p == nil || !p.Required || p.Schema.Type != "string" {
+		t.Errorf("path parameter %+v", p)
+	}
+	if p := params["query size"]; p == nil || p.Required || p.Schema.Type != "integer" {
+		t.Errorf("embedded query parameter %+v", p)
+	}
+	if p := params["header X-Trace-Id"]; p == nil {
+		t.Error("missing the header parameter")
+	}
+
+	body := create.RequestBody.Content["application/json"].Schema
+	if !create.RequestBody.Required || len(body.Properties) != 2 || !reflect.DeepEqual(body.Required, []string{"name"}) {
+		t.Errorf("request body %+v", body)
+	}
+	if body.Properties["name"].Description != "display name" {
+		t.Errorf("name property %+v", body.Properties["name"])
+	}
+
+	var statuses []string
+	for status := range create.Responses {
+		statuses = append(statuses, status)
+	}
+	for _, status := range []string{"200", "400", "403", "500", "504"} {
+		if create.Responses[status] == nil {
+			t.Errorf("missing response %s in %v", status, statuses)
+		}
+	}
+	data := create.Responses["200"].Content["application/json"].Schema.Properties["data"]
+	if data.Ref != "#/components/schemas/User" {
+		t.Errorf("response data %+v", data)
+	}
+	user := doc.Components.Schemas["User"]
+	if user == nil || user.Properties["manager"].Ref != "#/components/schemas/User" || user.Properties["created_at"].Format != "date-time" {
+		t.Errorf("user schema %+v", user)
+	}
+
+	get := doc.Paths["/api/users/{org}/{id}"].Get
+	if get == nil || !get.Deprecated {
+		t.Fatalf("deprecated GET %+v", get)
+	}
+	if len(get.Parameters) != 2 || get.Parameters[1].Schema.Type != "integer" || get.Parameters[0].Schema.Format != "uuid" {
+		t.Errorf("constrained parameters %+v %+v", get.Parameters[0], get.Parameters[1])
+	}
+}
+
+func TestDocumentEncoding(t *testing.T) {
+	doc := openapi.Generate(openapi.Info{Title: "test", Version: "1"}, routers.Describe("", servertest.Router{
+		Routes: map[string][]routers.RouteDesc{
+			"/files/*path": {{Method: http.MethodGet, Controller: []controllers.ControllerFunc{ok}}},
+			"/user_:name":  {{Method: http.MethodGet, Controller: []controllers.ControllerFunc{ok}}},
+		},
+	}))
+
+	data, err := doc.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["openapi"] != openapi.Version {
+		t.Errorf("json %s", data)
+	}
+	if _, ok := decoded["paths"].(map[string]interface{})["/files/{path}"]; !ok {
+		t.Errorf("catch-all path not converted: %s", data)
+	}
+	if _, ok := decoded["paths"].(map[string]interface{})["/user_{name}"]; !ok {
+		t.Errorf("prefixed parameter not converted: %s", data)
+	}
+
+	data, err = doc.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "openapi: 3.1.0\n") || !strings.Contains(string(data), "/files/{path}:") {
+		t.Errorf("yaml %s", data)
+	}
+}
@@ -0,0 +1,166 @@
+package openapi
+
+import (
+	"encoding"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	schemaNameInvalid = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// schemas collects the named struct schemas referenced from components.
+type schemas struct {
+	byType map[reflect.Type]string
+	named  map[string]*Schema
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		byType: make(map[reflect.Type]string),
+		named:  make(map[string]*Schema),
+	}
+}
+
+func (s *schemas) of(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() != reflect.Struct && reflect.PtrTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		return s.ref(t)
+	default:
+		return &Schema{}
+	}
+}
+
+func (s *schemas) ref(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return s.object(t, func(reflect.StructField) bool { return true })
+	}
+
+	name, ok := s.byType[t]
+	if !ok {
+		name = s.name(t)
+		s.byType[t] = name
+		// reserve the name first, self referencing types resolve to it
+		s.named[name] = nil
+		s.named[name] = s.object(t, func(reflect.StructField) bool { return true })
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (s *schemas) name(t reflect.Type) string {
+	base := schemaNameInvalid.ReplaceAllString(t.Name(), "_")
+	base = strings.Trim(base, "_")
+	name := base
+	if _, taken := s.named[name]; taken {
+		pkg := schemaNameInvalid.ReplaceAllString(t.PkgPath(), ".")
+		name = strings.Trim(pkg, ".") + "." + base
+	}
+	return name
+}
+
+// object builds an inline object schema from the json names of the fields
+// accepted by keep, embedded structs are flattened the way encoding/json does.
+func (s *schemas) object(t reflect.Type, keep func(reflect.StructField) bool) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.fields(schema, t, keep)
+	return schema
+}
+
+func (s *schemas) fields(schema *Schema, t reflect.Type, keep func(reflect.StructField) bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if field.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			s.fields(schema, ft, keep)
+			continue
+		}
+		if !field.IsExported() || !keep(field) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := s.of(field.Type)
+		if desc := field.Tag.Get("description"); desc != "" && prop.Ref == "" {
+			prop.Description = desc
+		}
+		schema.Properties[name] = prop
+		if isRequired(field) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+func jsonName(field reflect.StructField) (string, bool) {
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return "", false
+	}
+	return name, true
+}
+
+func tagName(field reflect.StructField, key string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func isRequired(field reflect.StructField) bool {
+	for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
@@ -134,11 +134,13 @@ func Recovery() gin.HandlerFunc {
 				path := c.Request.URL.Path
 				ip := c.ClientIP()
 				method := c.Request.Method
+				proto := c.Request.Proto
 				logger := loggers.Default(c)
 
 				if logger != nil {
 					logger.Name("request_panic").Error("",
 						"ip", ip,
+						"proto", proto,
 						"method", method,
 						"path", path,
 						"errMsg", errLog)
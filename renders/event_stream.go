@@ -0,0 +1,200 @@
+package renders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anyufly/gin_common/common"
+	"github.com/anyufly/gin_common/loggers"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultHeartbeat = 15 * time.Second
+	lastEventIDKey   = "Last-Event-ID"
+)
+
+// ErrNoEventSource is reported by an EventStream without Events nor Next.
+var ErrNoEventSource = errors.New("renders: EventStream needs Events or Next")
+
+// Event is a server-sent event. Data is written as is when it is a string or
+// a []byte, JSON encoded otherwise.
+type Event struct {
+	ID    string
+	Event string
+	Data  interface{}
+	Retry time.Duration
+}
+
+// EventStream streams Events, or the events of Next until it reports false,
+// as text/event-stream. Next gets the id of the last event sent, at first the
+// Last-Event-ID of the client, and must return once ctx is done. The stream
+// ends when the client leaves or the server stops, its stats go to the access
+// log.
+type EventStream struct {
+	Events <-chan Event
+	Next   func(ctx context.Context, lastEventID string) (Event, bool)
+	// Retry is the reconnection delay advised to the client.
+	Retry time.Duration
+	// Heartbeat keeps idle connections open, 15s by default, off when
+	// negative.
+	Heartbeat time.Duration
+}
+
+// LastEventID returns the id of the last event a reconnecting client received.
+func LastEventID(ctx *gin.Context) string {
+	return ctx.GetHeader(lastEventIDKey)
+}
+
+func (r EventStream) Render(ctx *gin.Context) {
+	if r.Events == nil && r.Next == nil {
+		loggers.LogRequestErr(ctx, ErrNoEventSource)
+		_ = ctx.Error(ErrNoEventSource)
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	lastEventID := LastEventID(ctx)
+	reqCtx := ctx.Request.Context()
+
+	streamCtx, cancel := context.WithCancel(reqCtx)
+	defer cancel()
+
+	events := r.Events
+	if r.Next != nil {
+		events = r.iterate(streamCtx, lastEventID)
+	}
+
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	if ctx.Request.ProtoMajor == 1 {
+		header.Set("Connection", "keep-alive")
+	}
+	header.Set("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	w := ctx.Writer
+	var err error
+	if r.Retry > 0 {
+		_, err = fmt.Fprintf(w, "retry: %d\n\n", r.Retry.Milliseconds())
+	}
+	w.Flush()
+
+	var heartbeat <-chan time.Time
+	interval := r.Heartbeat
+	if interval == 0 {
+		interval = defaultHeartbeat
+	}
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	sent := 0
+	reason := "completed"
+loop:
+	for err == nil {
+		select {
+		case <-reqCtx.Done():
+			reason = "client_closed"
+			break loop
+		case <-common.ServerStopping(reqCtx):
+			reason = "server_stopping"
+			break loop
+		case <-heartbeat:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		case event, ok := <-events:
+			if !ok {
+				break loop
+			}
+			if err = writeEvent(w, event); err != nil {
+				break
+			}
+			if event.ID != "" {
+				lastEventID = event.ID
+			}
+			sent++
+		}
+		w.Flush()
+	}
+	if err != nil {
+		reason = "write_error"
+	}
+
+	loggers.AddAccessLogFields(ctx,
+		"events", sent,
+		"lastEventId", lastEventID,
+		"reason", reason)
+}
+
+// iterate runs Next until it is done or ctx is cancelled.
+func (r EventStream) iterate(ctx context.Context, lastEventID string) <-chan Event {
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		for {
+			event, ok := r.Next(ctx, lastEventID)
+			if !ok {
+				return
+			}
+			if event.ID != "" {
+				lastEventID = event.ID
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
+
+func writeEvent(w io.Writer, event Event) error {
+	var b strings.Builder
+	if event.ID != "" {
+		b.WriteString("id: " + singleLine(event.ID) + "\n")
+	}
+	if event.Event != "" {
+		b.WriteString("event: " + singleLine(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+
+	var data string
+	switch d := event.Data.(type) {
+	case nil:
+	case string:
+		data = d
+	case []byte:
+		data = string(d)
+	default:
+		encoded, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		data = string(encoded)
+	}
+	data = strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\r", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
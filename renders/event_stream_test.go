@@ -0,0 +1,146 @@
+package renders
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anyufly/gin_common/loggers"
+	"github.com/gin-gonic/gin"
+)
+
+func TestWriteEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  string
+	}{
+		{name: "empty", event: Event{}, want: "data: \n\n"},
+		{
+			name:  "fields",
+			event: Event{ID: "7", Event: "update", Data: "hello", Retry: 2 * time.Second},
+			want:  "id: 7\nevent: update\nretry: 2000\ndata: hello\n\n",
+		},
+		{name: "multi-line data", event: Event{Data: "a\r\nb\rc\nd"}, want: "data: a\ndata: b\ndata: c\ndata: d\n\n"},
+		{name: "bytes", event: Event{Data: []byte("raw")}, want: "data: raw\n\n"},
+		{name: "json", event: Event{Data: map[string]int{"n": 1}}, want: "data: {\"n\":1}\n\n"},
+		{name: "single line fields", event: Event{ID: "1\n2", Event: "a\r\nb"}, want: "id: 12\nevent: ab\ndata: \n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := writeEvent(&b, tt.event); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("got %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+type recordingLogger struct {
+	fields []interface{}
+}
+
+func (l *recordingLogger) Name(name string) loggers.GinLogger { return l }
+func (l *recordingLogger) Info(msg string, keyAndValues ...interface{}) {
+	l.fields = keyAndValues
+}
+func (l *recordingLogger) Debug(msg string, keyAndValues ...interface{}) {}
+func (l *recordingLogger) Warn(msg string, keyAndValues ...interface{})  {}
+func (l *recordingLogger) Error(msg string, keyAndValues ...interface{}) {}
+
+// field returns the value logged for key.
+func (l *recordingLogger) field(key string) interface{} {
+	for i := 0; i+1 < len(l.fields); i += 2 {
+		if l.fields[i] == key {
+			return l.fields[i+1]
+		}
+	}
+	return nil
+}
+
+func serveStream(t *testing.T, stream EventStream, header http.Header) (*httptest.ResponseRecorder, *recordingLogger) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	logger := &recordingLogger{}
+	engine := gin.New()
+	engine.Use(loggers.Logger(logger))
+	engine.GET("/events", stream.Render)
+
+	r := httptest.NewRequest(http.MethodGet, "/events", nil)
+	for key, values := range header {
+		r.Header[key] = values
+	}
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		engine.ServeHTTP(w, r)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stream did not end")
+	}
+	return w, logger
+}
+
+func TestEventStreamResume(t *testing.T) {
+	var seen []string
+	next := func(ctx context.Context, lastEventID string) (Event, bool) {
+		seen = append(seen, lastEventID)
+		id, _ := strconv.Atoi(lastEventID)
+		if id >= 5 {
+			return Event{}, false
+		}
+		return Event{ID: strconv.Itoa(id + 1), Data: "tick"}, true
+	}
+
+	w, logger := serveStream(t, EventStream{Next: next, Retry: time.Second, Heartbeat: -1},
+		http.Header{"Last-Event-Id": {"3"}})
+
+	if want := []string{"3", "4", "5"}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("Next saw the ids %q, want %q", seen, want)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("content type %q", got)
+	}
+	if want := "retry: 1000\n\nid: 4\ndata: tick\n\nid: 5\ndata: tick\n\n"; w.Body.String() != want {
+		t.Errorf("body %q, want %q", w.Body.String(), want)
+	}
+	for key, want := range map[string]interface{}{"events": 2, "lastEventId": "5", "reason": "completed", "logType": "request"} {
+		if got := logger.field(key); got != want {
+			t.Errorf("access log %s = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestEventStreamEvents(t *testing.T) {
+	events := make(chan Event, 2)
+	events <- Event{Event: "a"}
+	events <- Event{Event: "b"}
+	close(events)
+
+	w, logger := serveStream(t, EventStream{Events: events, Heartbeat: -1}, nil)
+	if want := "event: a\ndata: \n\nevent: b\ndata: \n\n"; w.Body.String() != want {
+		t.Errorf("body %q, want %q", w.Body.String(), want)
+	}
+	if got := logger.field("events"); got != 2 {
+		t.Errorf("access log events = %v", got)
+	}
+}
+
+func TestEventStreamWithoutSource(t *testing.T) {
+	w, _ := serveStream(t, EventStream{}, nil)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
@@ -114,6 +114,11 @@ func (er *ErrorResponse) WithCode(code string) *ErrorResponse {
 
 var UnknownError = NewErrorResponse(http.StatusInternalServerError, "UnknownError", "未知错误")
 var ParameterError = NewErrorResponse(http.StatusBadRequest, "ParameterError", "参数错误")
+var ForbiddenError = NewErrorResponse(http.StatusForbidden, "Forbidden", "禁止访问")
+var RequestEntityTooLargeError = NewErrorResponse(http.StatusRequestEntityTooLarge, "RequestEntityTooLarge", "请求体过大")
+var TooManyRequestsError = NewErrorResponse(http.StatusTooManyRequests, "TooManyRequests", "请求过于频繁")
+var ServiceUnavailableError = NewErrorResponse(http.StatusServiceUnavailable, "ServiceUnavailable", "服务不可用")
+var GatewayTimeoutError = NewErrorResponse(http.StatusGatewayTimeout, "GatewayTimeout", "请求超时")
 var EmptyError = &ErrorResponse{
 	Response: &Response{
 		statusCode: http.StatusInternalServerError,
@@ -0,0 +1,241 @@
+package routers
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// engineOrigin names the routes that were on the engine before CombineRouters,
+// namedOrigin the names registered by a previous CombineRouters.
+const (
+	engineOrigin = "gin.Engine"
+	namedOrigin  = "registered name"
+)
+
+type RouteConflict struct {
+	Method  string
+	Path    string
+	Other   string
+	Origins []string
+	Reason  string
+}
+
+func (c RouteConflict) String() string {
+	route := strings.TrimSpace(c.Method + " " + c.Path)
+	if c.Other != "" && c.Other != c.Path {
+		route += " and " + c.Other
+	}
+	return fmt.Sprintf("%s: %s (%s)", route, c.Reason, strings.Join(c.Origins, ", "))
+}
+
+// RouteConflictError lists every route CombineRouters refused to register.
+type RouteConflictError struct {
+	Conflicts []RouteConflict
+}
+
+func (e *RouteConflictError) Error() string {
+	lines := make([]string, 0, len(e.Conflicts)+1)
+	lines = append(lines, fmt.Sprintf("routers: %d conflicting route(s)", len(e.Conflicts)))
+	for _, conflict := range e.Conflicts {
+		lines = append(lines, "  "+conflict.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+type checkedRoute struct {
+	method string
+	path   string
+	origin string
+}
+
+func routerOrigin(router Router) string {
+	return reflect.TypeOf(router).String()
+}
+
+// checkRoutes validates routes against each other and the routes of engine.
+func checkRoutes(engine *gin.Engine, routes []RouteInfo, registry *OptionRegistry) error {
+	var conflicts []RouteConflict
+	var checked []checkedRoute
+	for _, route := range engine.Routes() {
+		checked = append(checked, checkedRoute{method: route.Method, path: route.Path, origin: engineOrigin})
+	}
+
+	for _, route := range routes {
+		origin := routerOrigin(route.Router)
+		if strings.TrimSpace(route.Method) == "" {
+			conflicts = append(conflicts, RouteConflict{
+				Path: route.Path, Origins: []string{origin}, Reason: "empty method",
+			})
+			continue
+		}
+		if route.err != nil {
+			conflicts = append(conflicts, RouteConflict{
+				Method: route.Method, Path: route.Path, Origins: []string{origin}, Reason: route.err.Error(),
+			})
+			continue
+		}
+		if route.Desc.Options != nil {
+			if reason := route.Desc.Options.check(registry); reason != "" {
+				conflicts = append(conflicts, RouteConflict{
+					Method: route.Method, Path: route.Path, Origins: []string{origin}, Reason: reason,
+				})
+				continue
+			}
+		}
+		if reason := checkWildcards(route.Path); reason != "" {
+			conflicts = append(conflicts, RouteConflict{
+				Method: route.Method, Path: route.Path, Origins: []string{origin}, Reason: reason,
+			})
+			continue
+		}
+
+		for _, other := range checked {
+			if other.method != route.Method {
+				continue
+			}
+			if reason := pathConflict(other.path, route.Path); reason != "" {
+				conflicts = append(conflicts, RouteConflict{
+					Method:  route.Method,
+					Path:    other.path,
+					Other:   route.Path,
+					Origins: []string{other.origin, origin},
+					Reason:  reason,
+				})
+			}
+		}
+		checked = append(checked, checkedRoute{method: route.Method, path: route.Path, origin: origin})
+	}
+
+	conflicts = append(conflicts, checkNames(routes, registry)...)
+
+	if len(conflicts) > 0 {
+		return &RouteConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// checkNames reports names given to several routes or already registered in
+// registry, versions combined together share the unqualified names.
+func checkNames(routes []RouteInfo, registry *OptionRegistry) []RouteConflict {
+	var conflicts []RouteConflict
+	named := make(map[string]RouteInfo)
+	for _, route := range routes {
+		if route.Desc.Name == "" {
+			continue
+		}
+		name := qualifiedName(route)
+		if other, ok := named[name]; ok {
+			conflicts = append(conflicts, RouteConflict{
+				Method:  route.Method,
+				Path:    other.Path,
+				Other:   route.Path,
+				Origins: []string{routerOrigin(other.Router), routerOrigin(route.Router)},
+				Reason:  fmt.Sprintf("duplicate route name %q", name),
+			})
+			continue
+		}
+		named[name] = route
+
+		names := []string{name}
+		if route.Version != "" {
+			names = append(names, route.Desc.Name)
+		}
+		for _, name := range names {
+			if p, ok := registry.namedPath(name); ok && p != route.Path {
+				conflicts = append(conflicts, RouteConflict{
+					Method:  route.Method,
+					Path:    p,
+					Other:   route.Path,
+					Origins: []string{namedOrigin, routerOrigin(route.Router)},
+					Reason:  fmt.Sprintf("duplicate route name %q", name),
+				})
+			}
+		}
+	}
+	return conflicts
+}
+
+// checkWildcards reports the malformed wildcards gin would panic on.
+func checkWildcards(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		_, kind, name := splitWildcard(segment)
+		if kind == 0 {
+			continue
+		}
+		if strings.ContainsAny(name, ":*") {
+			return fmt.Sprintf("only one wildcard per path segment is allowed in %q", segment)
+		}
+		if name == "" {
+			return "wildcard must be named with a non-empty name"
+		}
+		if kind == '*' && segment[0] != '*' {
+			return fmt.Sprintf("catch-all must start the path segment %q", segment)
+		}
+		if kind == '*' && i != len(segments)-1 {
+			return "catch-all wildcard is only allowed at the end of the path"
+		}
+	}
+	return ""
+}
+
+// pathConflict compares two paths of the same method segment by segment.
+func pathConflict(a, b string) string {
+	if a == b {
+		return "duplicate route"
+	}
+
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, y := as[i], bs[i]
+		xp, xw, _ := splitWildcard(x)
+		yp, yw, _ := splitWildcard(y)
+
+		switch {
+		case xw == '*' || yw == '*':
+			if xw == '*' && yw == '*' && x == y {
+				return "duplicate route"
+			}
+			return fmt.Sprintf("catch-all %q conflicts with %s", pick(xw == '*', x, y), segmentName(pick(xw == '*', y, x)))
+		case xw == ':' && yw == ':' && xp == yp:
+			if x != y {
+				return fmt.Sprintf("wildcard %q conflicts with wildcard %q", x, y)
+			}
+		case xw == ':' || yw == ':':
+			// gin routes static segments ahead of a parameter
+			return ""
+		case x != y:
+			return ""
+		}
+	}
+
+	return ""
+}
+
+func segmentName(segment string) string {
+	if segment == "" {
+		return "the trailing slash"
+	}
+	return strconv.Quote(segment)
+}
+
+// splitWildcard splits a path segment around its wildcard, kind is 0 without
+// one.
+func splitWildcard(segment string) (prefix string, kind byte, name string) {
+	i := strings.IndexAny(segment, ":*")
+	if i < 0 {
+		return segment, 0, ""
+	}
+	return segment[:i], segment[i], segment[i+1:]
+}
+
+func pick(cond bool, a, b string) string {
+	if cond {
+		return a
+	}
+	return b
+}
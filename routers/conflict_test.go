@@ -0,0 +1,192 @@
+package routers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPathConflict(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		reason string
+	}{
+		{"/users", "/users", "duplicate route"},
+		{"/users", "/posts", ""},
+		{"/users/:id", "/users/new", ""},
+		{"/users/:id", "/users/:id", "duplicate route"},
+		{"/users/:id", "/users/:name", `wildcard ":id" conflicts with wildcard ":name"`},
+		{"/users/:id/posts", "/users/:name/comments", `wildcard ":id" conflicts with wildcard ":name"`},
+		{"/users/:id", "/users/:id/posts", ""},
+		{"/static/*filepath", "/static/*filepath", "duplicate route"},
+		{"/static/*filepath", "/static/index.html", `catch-all "*filepath" conflicts with "index.html"`},
+		{"/static/*filepath", "/static/", `catch-all "*filepath" conflicts with the trailing slash`},
+		{"/static/:name", "/static/*filepath", `catch-all "*filepath" conflicts with ":name"`},
+		{"/a/*rest", "/b/*rest", ""},
+		{"/user_:id", "/user_:name", `wildcard "user_:id" conflicts with wildcard "user_:name"`},
+		{"/user_:id", "/user_new", ""},
+		{"/user_:id", "/item_:name", ""},
+		{"/a:b", "/:c", ""},
+	}
+
+	for _, tt := range tests {
+		if got := pathConflict(tt.a, tt.b); got != tt.reason {
+			t.Errorf("pathConflict(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.reason)
+		}
+	}
+}
+
+func TestCheckWildcards(t *testing.T) {
+	tests := []struct {
+		path   string
+		reason string
+	}{
+		{"/users/:id", ""},
+		{"/static/*filepath", ""},
+		{"/users/:id:name", `only one wildcard per path segment is allowed in ":id:name"`},
+		{"/user_:name", ""},
+		{"/a/b:c", ""},
+		{"/a:b", ""},
+		{"/users/id:name:x", `only one wildcard per path segment is allowed in "id:name:x"`},
+		{"/users/:", "wildcard must be named with a non-empty name"},
+		{"/users/id:", "wildcard must be named with a non-empty name"},
+		{"/static/*filepath/more", "catch-all wildcard is only allowed at the end of the path"},
+		{"/static/file*path", `catch-all must start the path segment "file*path"`},
+	}
+
+	for _, tt := range tests {
+		if got := checkWildcards(tt.path); got != tt.reason {
+			t.Errorf("checkWildcards(%q) = %q, want %q", tt.path, got, tt.reason)
+		}
+	}
+}
+
+func TestCheckRoutes(t *testing.T) {
+	withAuthorizer := NewOptionRegistry()
+	withAuthorizer.SetAuthorizer(func(*gin.Context, []string) error { return nil })
+
+	tests := []struct {
+		name      string
+		existing  []string
+		routers   []Router
+		registry  *OptionRegistry
+		conflicts []string
+	}{
+		{
+			name: "no conflict",
+			routers: []Router{testRouter{name: "users", config: map[string][]RouteDesc{
+				"/:id":  {route("GET"), route("DELETE")},
+				"/new":  {route("GET")},
+				"/list": {route("GET")},
+			}}},
+		},
+		{
+			name: "parameters after a static prefix",
+			routers: []Router{testRouter{config: map[string][]RouteDesc{
+				"/user_:name": {route("GET")},
+				"/a/b:c":      {route("GET")},
+				"/a:b":        {route("GET")},
+			}}},
+		},
+		{
+			name: "duplicate across routers",
+			routers: []Router{
+				testRouter{name: "users", config: map[string][]RouteDesc{"/:id": {route("GET")}}},
+				testRouter{name: "users", config: map[string][]RouteDesc{"/:id": {route("GET")}}},
+			},
+			conflicts: []string{"duplicate route"},
+		},
+		{
+			name:     "conflict with the engine",
+			existing: []string{"/api/users/:name"},
+			routers: []Router{testRouter{name: "users", config: map[string][]RouteDesc{
+				"/:id": {route("GET")},
+			}}},
+			conflicts: []string{`wildcard ":name" conflicts with wildcard ":id" (gin.Engine`},
+		},
+		{
+			name: "empty method and bad constraint",
+			routers: []Router{testRouter{config: map[string][]RouteDesc{
+				"/a":       {route("")},
+				"/b/:id<>": {route("GET")},
+			}}},
+			conflicts: []string{"empty method", `empty constraint of parameter "id"`},
+		},
+		{
+			name: "duplicate names",
+			routers: []Router{testRouter{config: map[string][]RouteDesc{
+				"/a": {namedRoute("GET", "check.same")},
+				"/b": {namedRoute("GET", "check.same")},
+			}}},
+			conflicts: []string{`duplicate route name "check.same"`},
+		},
+		{
+			name: "options without authorizer or rate limit class",
+			routers: []Router{testRouter{config: map[string][]RouteDesc{
+				"/scoped":  {{Method: "GET", Options: &RouteOptions{Scopes: []string{"admin"}}}},
+				"/limited": {{Method: "GET", Options: &RouteOptions{RateLimitClass: "api"}}},
+			}}},
+			conflicts: []string{
+				`unknown rate limit class "api"`,
+				"scopes required but no authorizer set",
+			},
+		},
+		{
+			name:     "scopes with an authorizer",
+			registry: withAuthorizer,
+			routers: []Router{testRouter{config: map[string][]RouteDesc{
+				"/scoped": {{Method: "GET", Options: &RouteOptions{Scopes: []string{"admin"}}}},
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			for _, p := range tt.existing {
+				engine.GET(p, func(*gin.Context) {})
+			}
+			registry := tt.registry
+			if registry == nil {
+				registry = NewOptionRegistry()
+			}
+
+			err := checkRoutes(engine, Describe("/api", tt.routers...), registry)
+			if len(tt.conflicts) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var conflictErr *RouteConflictError
+			if !errors.As(err, &conflictErr) {
+				t.Fatalf("expected a *RouteConflictError, got %v", err)
+			}
+			if len(conflictErr.Conflicts) != len(tt.conflicts) {
+				t.Fatalf("expected %d conflicts, got %v", len(tt.conflicts), err)
+			}
+			for i, want := range tt.conflicts {
+				if got := conflictErr.Conflicts[i].String(); !strings.Contains(got, want) {
+					t.Errorf("conflict %d %q does not contain %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCombineRoutersRegistersNothingOnConflict(t *testing.T) {
+	engine := gin.New()
+	err := CombineRouters(engine, "/api",
+		testRouter{name: "a", config: map[string][]RouteDesc{"/x": {route("GET")}, "/y": {route("GET")}}},
+		testRouter{name: "a", config: map[string][]RouteDesc{"/y": {route("GET")}}},
+	)
+	if err == nil {
+		t.Fatal("expected a conflict")
+	}
+	if routes := engine.Routes(); len(routes) != 0 {
+		t.Errorf("expected no route registered, got %v", routes)
+	}
+}
@@ -0,0 +1,189 @@
+package routers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/anyufly/gin_common/common"
+	"github.com/anyufly/gin_common/response"
+	"github.com/anyufly/gin_common/trans"
+	"github.com/anyufly/gin_common/validators"
+	"github.com/gin-gonic/gin"
+)
+
+// ParamConstraint follows a parameter in GroupConfig paths, "/users/:id<int>".
+// Expr names a constraint registered with RegisterConstraint, or is a regular
+// expression the whole value, as common.Param returns it, must match.
+type ParamConstraint struct {
+	Expr string
+	// Pattern is the anchored regular expression of unnamed constraints.
+	Pattern string
+}
+
+var constraints = struct {
+	sync.RWMutex
+	funcs map[string]func(string) bool
+}{funcs: map[string]func(string) bool{
+	"int": func(v string) bool {
+		_, err := strconv.ParseInt(v, 10, 64)
+		return err == nil
+	},
+	"uint": func(v string) bool {
+		_, err := strconv.ParseUint(v, 10, 64)
+		return err == nil
+	},
+	"float": func(v string) bool {
+		_, err := strconv.ParseFloat(v, 64)
+		return err == nil
+	},
+	"bool": func(v string) bool {
+		_, err := strconv.ParseBool(v)
+		return err == nil
+	},
+	"uuid":  regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`).MatchString,
+	"alpha": regexp.MustCompile(`^[a-zA-Z]+$`).MatchString,
+	"alnum": regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString,
+}}
+
+// RegisterConstraint registers or replaces a named constraint, int, uint,
+// float, bool, uuid, alpha and alnum are built in.
+func RegisterConstraint(name string, fn func(value string) bool) {
+	constraints.Lock()
+	defer constraints.Unlock()
+	constraints.funcs[name] = fn
+}
+
+func constraintFunc(name string) (func(string) bool, bool) {
+	constraints.RLock()
+	defer constraints.RUnlock()
+	fn, ok := constraints.funcs[name]
+	return fn, ok
+}
+
+type paramCheck struct {
+	param      string
+	constraint ParamConstraint
+	match      func(string) bool
+}
+
+// stripConstraints removes the constraints, which may contain '/', from p.
+func stripConstraints(p string) (string, []paramCheck, error) {
+	var b strings.Builder
+	var checks []paramCheck
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		b.WriteByte(c)
+		if c != ':' && c != '*' {
+			continue
+		}
+
+		start := i + 1
+		end := start
+		for end < len(p) && p[end] != '/' && p[end] != '<' {
+			end++
+		}
+		param := p[start:end]
+		b.WriteString(param)
+		i = end - 1
+		if end == len(p) || p[end] != '<' {
+			continue
+		}
+
+		depth := 0
+		closing := -1
+		for j := end; j < len(p); j++ {
+			switch p[j] {
+			case '\\':
+				j++
+			case '<':
+				depth++
+			case '>':
+				depth--
+			}
+			if depth == 0 {
+				closing = j
+				break
+			}
+		}
+		if closing < 0 {
+			return "", nil, fmt.Errorf("unterminated constraint of parameter %q", param)
+		}
+
+		check, err := newParamCheck(param, p[end+1:closing])
+		if err != nil {
+			return "", nil, err
+		}
+		checks = append(checks, check)
+		i = closing
+	}
+	return b.String(), checks, nil
+}
+
+func newParamCheck(param string, expr string) (paramCheck, error) {
+	if expr == "" {
+		return paramCheck{}, fmt.Errorf("empty constraint of parameter %q", param)
+	}
+	if fn, ok := constraintFunc(expr); ok {
+		return paramCheck{param: param, constraint: ParamConstraint{Expr: expr}, match: fn}, nil
+	}
+
+	pattern := "^(?:" + expr + ")$"
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return paramCheck{}, fmt.Errorf("invalid constraint of parameter %q: %w", param, err)
+	}
+	return paramCheck{
+		param:      param,
+		constraint: ParamConstraint{Expr: expr, Pattern: pattern},
+		match:      re.MatchString,
+	}, nil
+}
+
+func constraintMap(checks []paramCheck) map[string]ParamConstraint {
+	if len(checks) == 0 {
+		return nil
+	}
+	m := make(map[string]ParamConstraint, len(checks))
+	for _, check := range checks {
+		m[check.param] = check.constraint
+	}
+	return m
+}
+
+// paramChecker answers response.ParameterError to invalid path parameters.
+func paramChecker(checks []paramCheck) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		data := make(map[string]string)
+		for _, check := range checks {
+			value := common.Param(ctx, check.param)
+			if check.match(value) {
+				continue
+			}
+			data[check.param] = constraintMessage(check)
+		}
+		if len(data) == 0 {
+			return
+		}
+
+		msgs := make([]string, 0, len(checks))
+		for _, check := range checks {
+			if msg, ok := data[check.param]; ok {
+				msgs = append(msgs, msg)
+			}
+		}
+		response.ParameterError.WithMsg(strings.Join(msgs, "; ")).WithData(data).Render(ctx)
+		ctx.Abort()
+	}
+}
+
+func constraintMessage(check paramCheck) string {
+	if t := trans.Trans(); t != nil {
+		if msg, err := t.T(validators.PathParamTag, check.param, check.constraint.Expr); err == nil {
+			return msg
+		}
+	}
+	return fmt.Sprintf("%s must match %s", check.param, check.constraint.Expr)
+}
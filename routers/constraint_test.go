@@ -0,0 +1,134 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStripConstraints(t *testing.T) {
+	tests := []struct {
+		path        string
+		stripped    string
+		constraints map[string]ParamConstraint
+		wantErr     bool
+	}{
+		{path: "/users", stripped: "/users"},
+		{path: "/users/:id", stripped: "/users/:id"},
+		{
+			path:        "/users/:id<int>",
+			stripped:    "/users/:id",
+			constraints: map[string]ParamConstraint{"id": {Expr: "int"}},
+		},
+		{
+			path:     "/users/:id<uuid>/posts/:post<uint>",
+			stripped: "/users/:id/posts/:post",
+			constraints: map[string]ParamConstraint{
+				"id":   {Expr: "uuid"},
+				"post": {Expr: "uint"},
+			},
+		},
+		{
+			path:        `/files/:name<[a-z]+\.txt>`,
+			stripped:    "/files/:name",
+			constraints: map[string]ParamConstraint{"name": {Expr: `[a-z]+\.txt`, Pattern: `^(?:[a-z]+\.txt)$`}},
+		},
+		{
+			path:        "/dates/:day<\\d{4}/\\d{2}>",
+			stripped:    "/dates/:day",
+			constraints: map[string]ParamConstraint{"day": {Expr: `\d{4}/\d{2}`, Pattern: `^(?:\d{4}/\d{2})$`}},
+		},
+		{
+			path:        "/tags/:tag<<[a-z]+>>",
+			stripped:    "/tags/:tag",
+			constraints: map[string]ParamConstraint{"tag": {Expr: "<[a-z]+>", Pattern: "^(?:<[a-z]+>)$"}},
+		},
+		{path: "/static/*filepath", stripped: "/static/*filepath"},
+		{path: "/users/:id<int", wantErr: true},
+		{path: "/users/:id<>", wantErr: true},
+		{path: "/users/:id<[a-z>", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			stripped, checks, err := stripConstraints(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", stripped)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stripped != tt.stripped {
+				t.Errorf("stripped path %q, want %q", stripped, tt.stripped)
+			}
+			if got := constraintMap(checks); !reflect.DeepEqual(got, tt.constraints) {
+				t.Errorf("constraints %v, want %v", got, tt.constraints)
+			}
+		})
+	}
+}
+
+func TestParamCheckMatch(t *testing.T) {
+	tests := []struct {
+		expr  string
+		value string
+		match bool
+	}{
+		{"int", "42", true},
+		{"int", "-42", true},
+		{"int", "4.2", false},
+		{"uint", "-1", false},
+		{"bool", "true", true},
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", true},
+		{"uuid", "123e4567", false},
+		{"alpha", "abc", true},
+		{"alpha", "abc1", false},
+		{`[a-z]+`, "abc", true},
+		{`[a-z]+`, "abc/def", false},
+		{`a|b`, "ab", false},
+	}
+
+	for _, tt := range tests {
+		check, err := newParamCheck("p", tt.expr)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.expr, err)
+		}
+		if got := check.match(tt.value); got != tt.match {
+			t.Errorf("%s matching %q: got %v, want %v", tt.expr, tt.value, got, tt.match)
+		}
+	}
+}
+
+func TestParamChecker(t *testing.T) {
+	engine := gin.New()
+	err := CombineRouters(engine, "", testRouter{config: map[string][]RouteDesc{
+		"/users/:id<int>":            {route(http.MethodGet)},
+		`/files/*path<[a-z/]+\.txt>`: {route(http.MethodGet)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target string
+		status int
+	}{
+		{"/users/42", http.StatusOK},
+		{"/users/bob", http.StatusBadRequest},
+		{"/files/docs/a.txt", http.StatusOK},
+		{"/files/docs/a.pdf", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if w.Code != tt.status {
+			t.Errorf("GET %s answered %d, want %d", tt.target, w.Code, tt.status)
+		}
+	}
+}
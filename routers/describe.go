@@ -0,0 +1,86 @@
+package routers
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/anyufly/gin_common/response"
+)
+
+// RouteDoc documents a route for the generated OpenAPI document. Request and
+// Response are sample values of the bound request and of the response data.
+type RouteDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	OperationID string
+	Request     interface{}
+	Response    interface{}
+	Errors      []*response.ErrorResponse
+}
+
+type RouteInfo struct {
+	Method    string
+	Path      string
+	GroupName string
+	// Version is the name of the Version the route was combined under.
+	Version string
+	Router  Router
+	Desc    RouteDesc
+	// Constraints of the path parameters, stripped from Path.
+	Constraints map[string]ParamConstraint
+
+	err    error
+	checks []paramCheck
+	// set by resolveRoutes
+	group       *routeGroup
+	middlewares []resolvedMiddleware
+	resolved    bool
+}
+
+func groupBasePath(basePath string, groupName string) string {
+	base := joinPaths("/", basePath)
+	trimName := strings.Trim(groupName, " ")
+	if trimName == "" {
+		return base
+	}
+	return joinPaths(base, trimName)
+}
+
+// Describe lists the routes CombineRouters registers for the same arguments,
+// sorted by path and method.
+func Describe(basePath string, routers ...Router) []RouteInfo {
+	var routes []RouteInfo
+	for _, router := range routers {
+		groupName := router.GroupName()
+		groupPath := groupBasePath(basePath, groupName)
+		group := &routeGroup{midList: router.GroupMiddleware()}
+		for relativePath, routeDescribes := range router.GroupConfig() {
+			strippedPath, checks, err := stripConstraints(relativePath)
+			if err != nil {
+				strippedPath = relativePath
+			}
+			for _, routeDesc := range routeDescribes {
+				routes = append(routes, RouteInfo{
+					Method:      strings.ToUpper(routeDesc.Method),
+					Path:        calculateAbsolutePath(groupPath, strippedPath),
+					GroupName:   groupName,
+					Router:      router,
+					Desc:        routeDesc,
+					Constraints: constraintMap(checks),
+					err:         err,
+					checks:      checks,
+					group:       group,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
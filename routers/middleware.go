@@ -0,0 +1,260 @@
+package routers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/anyufly/gin_common/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// Middlewares are middlewares.IMiddleWare or middlewares.MiddlewareFunc
+// values, a MiddlewareFunc is called once when the routes are combined. A
+// middleware is identified by its ID() when it implements
+// middlewares.IdentifiedMiddleWare, by its instance otherwise, and never when
+// a MiddlewareFunc returned it: closures of a factory share their code. Only
+// the first middleware of an identity runs.
+
+// resolvedMiddleware is a middleware of a list with its MiddlewareFunc called.
+type resolvedMiddleware struct {
+	source     interface{}
+	middleware middlewares.IMiddleWare
+}
+
+func resolveMiddlewares(midList []interface{}) []resolvedMiddleware {
+	resolved := make([]resolvedMiddleware, 0, len(midList))
+	for _, middleware := range midList {
+		switch m := middleware.(type) {
+		case middlewares.IMiddleWare:
+			resolved = append(resolved, resolvedMiddleware{source: m, middleware: m})
+		case middlewares.MiddlewareFunc:
+			resolved = append(resolved, resolvedMiddleware{source: m, middleware: m()})
+		}
+	}
+	return resolved
+}
+
+type middlewareKey struct {
+	kind  string
+	id    string
+	value interface{}
+}
+
+func middlewareIdentity(resolved resolvedMiddleware) (middlewareKey, bool) {
+	if m, ok := resolved.middleware.(middlewares.IdentifiedMiddleWare); ok {
+		return middlewareKey{kind: "id", id: m.ID()}, true
+	}
+	if _, ok := resolved.source.(middlewares.MiddlewareFunc); ok || resolved.middleware == nil {
+		return middlewareKey{}, false
+	}
+
+	v := reflect.ValueOf(resolved.middleware)
+	if v.Kind() == reflect.Pointer {
+		return middlewareKey{kind: "instance", id: v.Type().String(), value: v.Pointer()}, true
+	}
+	if comparableValue(v) {
+		return middlewareKey{kind: "instance", value: resolved.middleware}, true
+	}
+	return middlewareKey{}, false
+}
+
+// comparableValue reports whether v can be used as a map key without
+// panicking.
+func comparableValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface:
+		return v.IsNil() || comparableValue(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !comparableValue(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !comparableValue(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return v.Type().Comparable()
+	}
+}
+
+type middlewareSet map[middlewareKey]bool
+
+// add reports whether middleware was not in the set yet.
+func (set middlewareSet) add(middleware resolvedMiddleware) bool {
+	key, ok := middlewareIdentity(middleware)
+	if !ok {
+		return true
+	}
+	if set[key] {
+		return false
+	}
+	set[key] = true
+	return true
+}
+
+func (set middlewareSet) has(middleware resolvedMiddleware) bool {
+	key, ok := middlewareIdentity(middleware)
+	return ok && set[key]
+}
+
+func dedupeGroupMiddlewares(midList []interface{}) ([]resolvedMiddleware, middlewareSet) {
+	var deduped []resolvedMiddleware
+	var applied = make(middlewareSet)
+	for _, middleware := range resolveMiddlewares(midList) {
+		if applied.add(middleware) {
+			deduped = append(deduped, middleware)
+		}
+	}
+	return deduped, applied
+}
+
+func dedupeRouteMiddlewares(midList []interface{}, groupApplied middlewareSet) []resolvedMiddleware {
+	var deduped []resolvedMiddleware
+	var applied = make(middlewareSet)
+	for _, middleware := range resolveMiddlewares(midList) {
+		if groupApplied.has(middleware) {
+			continue
+		}
+		if applied.add(middleware) {
+			deduped = append(deduped, middleware)
+		}
+	}
+	return deduped
+}
+
+// routeGroup holds the middlewares of a Router, resolved once.
+type routeGroup struct {
+	midList     []interface{}
+	resolved    bool
+	middlewares []resolvedMiddleware
+	applied     middlewareSet
+}
+
+func (group *routeGroup) resolve() {
+	if !group.resolved {
+		group.middlewares, group.applied = dedupeGroupMiddlewares(group.midList)
+		group.resolved = true
+	}
+}
+
+// resolveRoutes calls the MiddlewareFunc of the routes not resolved yet.
+func resolveRoutes(routes []RouteInfo) {
+	for i := range routes {
+		route := &routes[i]
+		if route.resolved {
+			continue
+		}
+		if route.group == nil {
+			route.group = &routeGroup{}
+			if route.Router != nil {
+				route.group.midList = route.Router.GroupMiddleware()
+			}
+		}
+		route.group.resolve()
+		route.middlewares = dedupeRouteMiddlewares(route.Desc.MiddleWare, route.group.applied)
+		route.resolved = true
+	}
+}
+
+func middlewareHandlers(midList []resolvedMiddleware) []gin.HandlerFunc {
+	var handlers []gin.HandlerFunc
+	for _, middleware := range midList {
+		if middleware.middleware != nil {
+			handlers = append(handlers, middlewares.MiddlewareHandler(middleware.middleware))
+		}
+	}
+	return handlers
+}
+
+func supportedMiddleware(middleware interface{}) bool {
+	switch m := middleware.(type) {
+	case middlewares.IMiddleWare:
+		return !isNilValue(m)
+	case middlewares.MiddlewareFunc:
+		return m != nil
+	default:
+		return false
+	}
+}
+
+func isNilValue(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+type UnsupportedMiddleware struct {
+	Router string
+	Path   string
+	Method string
+	Index  int
+	Value  interface{}
+}
+
+func (m UnsupportedMiddleware) String() string {
+	where := "group middleware"
+	if m.Path != "" {
+		where = fmt.Sprintf("middleware of %s %s", strings.ToUpper(m.Method), m.Path)
+	}
+	value := fmt.Sprintf("%T", m.Value)
+	switch {
+	case m.Value == nil:
+		value = "nil"
+	case isNilValue(m.Value):
+		value = "nil " + value
+	}
+	return fmt.Sprintf("%s #%d of %s is %s, want middlewares.IMiddleWare or middlewares.MiddlewareFunc",
+		where, m.Index, m.Router, value)
+}
+
+// UnsupportedMiddlewareError lists the middlewares CombineRouters can not
+// apply.
+type UnsupportedMiddlewareError struct {
+	Middlewares []UnsupportedMiddleware
+}
+
+func (e *UnsupportedMiddlewareError) Error() string {
+	lines := make([]string, 0, len(e.Middlewares)+1)
+	lines = append(lines, fmt.Sprintf("routers: %d unsupported middleware(s)", len(e.Middlewares)))
+	for _, m := range e.Middlewares {
+		lines = append(lines, "  "+m.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func checkMiddlewares(routers []Router, routes []RouteInfo) error {
+	var unsupported []UnsupportedMiddleware
+	for _, router := range routers {
+		for i, middleware := range router.GroupMiddleware() {
+			if !supportedMiddleware(middleware) {
+				unsupported = append(unsupported, UnsupportedMiddleware{
+					Router: routerOrigin(router), Index: i, Value: middleware,
+				})
+			}
+		}
+	}
+	for _, route := range routes {
+		for i, middleware := range route.Desc.MiddleWare {
+			if !supportedMiddleware(middleware) {
+				unsupported = append(unsupported, UnsupportedMiddleware{
+					Router: routerOrigin(route.Router), Path: route.Path, Method: route.Method, Index: i, Value: middleware,
+				})
+			}
+		}
+	}
+
+	if len(unsupported) > 0 {
+		return &UnsupportedMiddlewareError{Middlewares: unsupported}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package routers
+
+import (
+	"testing"
+
+	"github.com/anyufly/gin_common/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+type testMiddleware struct {
+	name string
+}
+
+func (m testMiddleware) Before(ctx *gin.Context) interface{} { return nil }
+func (m testMiddleware) After(ctx *gin.Context) interface{}  { return nil }
+func (m testMiddleware) DeniedBeforeAbortContext() bool      { return false }
+func (m testMiddleware) AllowAfterAbortContext() bool        { return false }
+
+type identifiedMiddleware struct {
+	testMiddleware
+}
+
+func (m identifiedMiddleware) ID() string { return m.name }
+
+func role(name string) middlewares.MiddlewareFunc {
+	return func() middlewares.IMiddleWare {
+		return testMiddleware{name: name}
+	}
+}
+
+func identifiedRole(name string) middlewares.MiddlewareFunc {
+	return func() middlewares.IMiddleWare {
+		return identifiedMiddleware{testMiddleware{name: name}}
+	}
+}
+
+func TestDedupeMiddlewares(t *testing.T) {
+	shared := &testMiddleware{name: "shared"}
+	admin := role("admin")
+
+	tests := []struct {
+		name   string
+		group  []interface{}
+		route  []interface{}
+		groups int
+		routes int
+	}{
+		{
+			name:   "closures of a factory are kept",
+			group:  []interface{}{role("admin"), role("user")},
+			route:  []interface{}{role("admin")},
+			groups: 2,
+			routes: 1,
+		},
+		{
+			name:   "the same func value is kept too",
+			group:  []interface{}{admin, admin},
+			groups: 2,
+		},
+		{
+			name:   "identified funcs are deduplicated by ID",
+			group:  []interface{}{identifiedRole("admin"), identifiedRole("admin"), identifiedRole("user")},
+			route:  []interface{}{identifiedRole("user"), identifiedRole("owner")},
+			groups: 2,
+			routes: 1,
+		},
+		{
+			name:   "instances are deduplicated",
+			group:  []interface{}{shared, testMiddleware{name: "value"}, shared, testMiddleware{name: "value"}},
+			route:  []interface{}{shared, &testMiddleware{name: "shared"}},
+			groups: 2,
+			routes: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, applied := dedupeGroupMiddlewares(tt.group)
+			if len(groups) != tt.groups {
+				t.Errorf("kept %d group middlewares, want %d", len(groups), tt.groups)
+			}
+			if routes := dedupeRouteMiddlewares(tt.route, applied); len(routes) != tt.routes {
+				t.Errorf("kept %d route middlewares, want %d", len(routes), tt.routes)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package routers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anyufly/gin_common/loggers"
+	"github.com/anyufly/gin_common/renders"
+	"github.com/anyufly/gin_common/response"
+	"github.com/gin-gonic/gin"
+)
+
+// RouteOptions become handlers running after the route middlewares, in the
+// order: cache policy, rate limit, scopes, body limit and timeout.
+type RouteOptions struct {
+	// Timeout bounds the request context, controllers have to watch it. A
+	// controller silent past the deadline gets response.GatewayTimeoutError.
+	Timeout time.Duration `json:"-"`
+	// MaxBodyBytes rejects larger bodies with 413.
+	MaxBodyBytes int64 `json:"max_body_bytes,omitempty"`
+	// Scopes are checked by the Authorizer of the registry.
+	Scopes       []string `json:"scopes,omitempty"`
+	CacheControl string   `json:"cache_control,omitempty"`
+	// RateLimitClass names a limiter of the registry.
+	RateLimitClass string `json:"rate_limit_class,omitempty"`
+}
+
+type routeOptionsJSON RouteOptions
+
+// MarshalJSON writes Timeout as a duration string.
+func (opts RouteOptions) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Timeout string `json:"timeout,omitempty"`
+		routeOptionsJSON
+	}{routeOptionsJSON: routeOptionsJSON(opts)}
+	if opts.Timeout > 0 {
+		v.Timeout = opts.Timeout.String()
+	}
+	return json.Marshal(v)
+}
+
+// Authorizer reports whether the request holds the scopes, errors other than
+// renders.ErrorRender answer response.ForbiddenError.
+type Authorizer func(ctx *gin.Context, scopes []string) error
+
+type RateLimiter interface {
+	Allow(ctx *gin.Context) bool
+}
+
+// OptionRegistry holds the authorizer, the rate limit classes and the names
+// of the routes combined with it.
+type OptionRegistry struct {
+	mu           sync.RWMutex
+	authorizer   Authorizer
+	rateLimiters map[string]RateLimiter
+	routes       []RouteInfo
+	names        map[string]string
+}
+
+func NewOptionRegistry() *OptionRegistry {
+	return &OptionRegistry{
+		rateLimiters: make(map[string]RateLimiter),
+		names:        make(map[string]string),
+	}
+}
+
+func (registry *OptionRegistry) SetAuthorizer(authorizer Authorizer) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.authorizer = authorizer
+}
+
+// RegisterRateLimitClass registers or replaces the limiter of class.
+func (registry *OptionRegistry) RegisterRateLimitClass(class string, limiter RateLimiter) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.rateLimiters[class] = limiter
+}
+
+// Routes returns the combined routes with the middlewares they were
+// registered with.
+func (registry *OptionRegistry) Routes() []RouteInfo {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return append([]RouteInfo(nil), registry.routes...)
+}
+
+func (registry *OptionRegistry) record(routes []RouteInfo) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.routes = append(registry.routes, routes...)
+}
+
+func (registry *OptionRegistry) currentAuthorizer() Authorizer {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return registry.authorizer
+}
+
+func (registry *OptionRegistry) rateLimiter(class string) (RateLimiter, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	limiter, ok := registry.rateLimiters[class]
+	return limiter, ok
+}
+
+// check reports the options that can not be honored.
+func (opts *RouteOptions) check(registry *OptionRegistry) string {
+	switch {
+	case opts.Timeout < 0:
+		return "negative timeout"
+	case opts.MaxBodyBytes < 0:
+		return "negative max body bytes"
+	case len(opts.Scopes) > 0 && registry.currentAuthorizer() == nil:
+		return "scopes required but no authorizer set"
+	}
+	if opts.RateLimitClass != "" {
+		if _, ok := registry.rateLimiter(opts.RateLimitClass); !ok {
+			return fmt.Sprintf("unknown rate limit class %q", opts.RateLimitClass)
+		}
+	}
+	return ""
+}
+
+func (opts *RouteOptions) handlers(registry *OptionRegistry) []gin.HandlerFunc {
+	var handlers []gin.HandlerFunc
+	if opts.CacheControl != "" {
+		handlers = append(handlers, cacheControlHandler(opts.CacheControl))
+	}
+	if opts.RateLimitClass != "" {
+		handlers = append(handlers, rateLimitHandler(registry, opts.RateLimitClass))
+	}
+	if len(opts.Scopes) > 0 {
+		handlers = append(handlers, scopesHandler(registry, opts.Scopes))
+	}
+	if opts.MaxBodyBytes > 0 {
+		handlers = append(handlers, maxBodyHandler(opts.MaxBodyBytes))
+	}
+	if opts.Timeout > 0 {
+		handlers = append(handlers, timeoutHandler(opts.Timeout))
+	}
+	return handlers
+}
+
+// String lists the options set, as shown by the route table.
+func (opts *RouteOptions) String() string {
+	if opts == nil {
+		return ""
+	}
+	var parts []string
+	if opts.Timeout > 0 {
+		parts = append(parts, "timeout="+opts.Timeout.String())
+	}
+	if opts.MaxBodyBytes > 0 {
+		parts = append(parts, fmt.Sprintf("max_body=%d", opts.MaxBodyBytes))
+	}
+	if len(opts.Scopes) > 0 {
+		parts = append(parts, "scopes="+strings.Join(opts.Scopes, ","))
+	}
+	if opts.CacheControl != "" {
+		parts = append(parts, "cache="+opts.CacheControl)
+	}
+	if opts.RateLimitClass != "" {
+		parts = append(parts, "rate_limit="+opts.RateLimitClass)
+	}
+	return strings.Join(parts, " ")
+}
+
+func cacheControlHandler(policy string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Cache-Control", policy)
+	}
+}
+
+func rateLimitHandler(registry *OptionRegistry, class string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if limiter, ok := registry.rateLimiter(class); ok && !limiter.Allow(ctx) {
+			response.TooManyRequestsError.Render(ctx)
+			ctx.Abort()
+		}
+	}
+}
+
+func scopesHandler(registry *OptionRegistry, scopes []string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorizer := registry.currentAuthorizer()
+		if authorizer == nil {
+			response.ForbiddenError.Render(ctx)
+			ctx.Abort()
+			return
+		}
+
+		err := authorizer(ctx, scopes)
+		if err == nil {
+			return
+		}
+		loggers.LogRequestErr(ctx, err)
+		if r, ok := err.(renders.ErrorRender); ok {
+			r.Render(ctx)
+		} else {
+			response.ForbiddenError.WithErr(err).Render(ctx)
+		}
+		ctx.Abort()
+	}
+}
+
+func maxBodyHandler(limit int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.ContentLength > limit {
+			response.RequestEntityTooLargeError.Render(ctx)
+			ctx.Abort()
+			return
+		}
+		if ctx.Request.Body != nil {
+			ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limit)
+		}
+	}
+}
+
+func timeoutHandler(timeout time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		timeoutCtx, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+		defer cancel()
+		*ctx.Request = *ctx.Request.WithContext(timeoutCtx)
+
+		ctx.Next()
+
+		if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) && !ctx.Writer.Written() {
+			response.GatewayTimeoutError.WithErr(timeoutCtx.Err()).Render(ctx)
+		}
+	}
+}
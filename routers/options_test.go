@@ -0,0 +1,89 @@
+package routers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anyufly/gin_common/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+// userMiddleware puts the X-User header in the context, or fails with err.
+type userMiddleware struct {
+	err error
+}
+
+func (m userMiddleware) Before(ctx *gin.Context) interface{} {
+	if m.err != nil {
+		return m.err
+	}
+	ctx.Set("user", ctx.GetHeader("X-User"))
+	return nil
+}
+func (m userMiddleware) After(ctx *gin.Context) interface{} { return nil }
+func (m userMiddleware) DeniedBeforeAbortContext() bool     { return false }
+func (m userMiddleware) AllowAfterAbortContext() bool       { return false }
+
+func TestRouteOptionsHandlers(t *testing.T) {
+	slow := func(ctx *gin.Context) interface{} {
+		<-ctx.Request.Context().Done()
+		return nil
+	}
+	registry := NewOptionRegistry()
+	registry.SetAuthorizer(func(ctx *gin.Context, scopes []string) error {
+		if ctx.GetString("user") != "admin" {
+			return errors.New("not an admin")
+		}
+		return nil
+	})
+
+	engine := gin.New()
+	err := registry.CombineRouters(engine, "", testRouter{config: map[string][]RouteDesc{
+		"/scoped": {{
+			Method:     http.MethodGet,
+			MiddleWare: []interface{}{userMiddleware{}},
+			Controller: []controllers.ControllerFunc{okController},
+			Options:    &RouteOptions{Scopes: []string{"admin"}},
+		}},
+		"/slow": {{
+			Method:     http.MethodGet,
+			Controller: []controllers.ControllerFunc{slow},
+			Options:    &RouteOptions{Timeout: 10 * time.Millisecond},
+		}},
+		"/expired": {{
+			Method:     http.MethodGet,
+			MiddleWare: []interface{}{userMiddleware{err: context.DeadlineExceeded}},
+			Controller: []controllers.ControllerFunc{okController},
+		}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		user   string
+		status int
+	}{
+		{name: "scopes see the route middlewares", target: "/scoped", user: "admin", status: http.StatusOK},
+		{name: "missing scope", target: "/scoped", user: "guest", status: http.StatusForbidden},
+		{name: "silent controller past the deadline", target: "/slow", status: http.StatusGatewayTimeout},
+		{name: "middleware returning a deadline", target: "/expired", status: http.StatusGatewayTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			r.Header.Set("X-User", tt.user)
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
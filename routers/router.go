@@ -8,14 +8,19 @@ import (
 
 	"github.com/anyufly/gin_common/common"
 	"github.com/anyufly/gin_common/controllers"
-	"github.com/anyufly/gin_common/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
 type RouteDesc struct {
+	// Name identifies the route for URLFor.
+	Name       string
 	Method     string
 	MiddleWare []interface{}
 	Controller []controllers.ControllerFunc
+	Doc        *RouteDoc
+	// Deprecation overrides the one of its Version.
+	Deprecation *Deprecation
+	Options     *RouteOptions
 }
 
 type Router interface {
@@ -24,55 +29,25 @@ type Router interface {
 	GroupMiddleware() []interface{}
 }
 
-func handleRouter(version *gin.RouterGroup, router Router) {
-	groupName := router.GroupName()
-	groupConfigs := router.GroupConfig()
-	groupMiddlewares := router.GroupMiddleware()
-	group := handleGroupName(version, groupName)
-	middlewareFlag := handleGroupMiddleware(group, groupMiddlewares...)
-	handleGroupConfigs(group, groupConfigs, middlewareFlag)
-}
-
-func handleGroupMiddleware(group *gin.RouterGroup, midList ...interface{}) map[int]bool {
-	var middlewareHandlers []gin.HandlerFunc
-	var middlewareFlag = make(map[int]bool)
-	for _, middleware := range midList {
-		mp := (*int)(unsafe.Pointer(&middleware))
-		if _, ok := middlewareFlag[*mp]; !ok {
-			middlewareFlag[*mp] = true
-
-			switch middleware.(type) {
-			case middlewares.IMiddleWare:
-				middlewareHandlers = append(middlewareHandlers,
-					middlewares.MiddlewareHandler(middleware.(middlewares.IMiddleWare)))
-			case middlewares.MiddlewareFunc:
-				fn := middleware.(middlewares.MiddlewareFunc)
-				mid := fn()
-				middlewareHandlers = append(middlewareHandlers,
-					middlewares.MiddlewareHandler(mid))
-			default:
-
-			}
+func dedupeControllers(controllerList []controllers.ControllerFunc) []controllers.ControllerFunc {
+	var deduped []controllers.ControllerFunc
+	var controllerFlag = make(map[int]bool)
+	for _, controller := range controllerList {
+		cp := (*int)(unsafe.Pointer(&controller))
+		if _, ok := controllerFlag[*cp]; !ok {
+			controllerFlag[*cp] = true
+			deduped = append(deduped, controller)
 		}
 	}
-	group.Use(middlewareHandlers...)
-	return middlewareFlag
-}
-
-func handleGroupName(version *gin.RouterGroup, groupName string) *gin.RouterGroup {
-	trimName := strings.Trim(groupName, " ")
-	if trimName == "" {
-		return version
-	}
-	return version.Group(trimName)
+	return deduped
 }
 
 type RequestFunc func(group *gin.RouterGroup, relativePath string, handlers ...gin.HandlerFunc) gin.IRoutes
 
-func addRouterFullPath(basePath string, relativePath string) gin.HandlerFunc {
+func addRouterFullPath(fullPath string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		reqCtx := ctx.Request.Context()
-		valCtx := context.WithValue(reqCtx, common.ContextKey("router_path"), calculateAbsolutePath(basePath, relativePath))
+		valCtx := context.WithValue(reqCtx, common.ContextKey("router_path"), fullPath)
 		*ctx.Request = *ctx.Request.WithContext(valCtx)
 	}
 }
@@ -108,57 +83,51 @@ func getFuncByMethod(method string) RequestFunc {
 	}
 }
 
-func handleGroupConfigs(group *gin.RouterGroup, groupConfigs map[string][]RouteDesc, alreadyUseMiddlewareFlag map[int]bool) {
-	for relativePath, routeDescribes := range groupConfigs {
-		for _, routeDesc := range routeDescribes {
-			method := routeDesc.Method
-
-			var controllerHandlers []gin.HandlerFunc
-			var middlewareFlag = make(map[int]bool)
-
-			for _, middleware := range routeDesc.MiddleWare {
-				mp := (*int)(unsafe.Pointer(&middleware))
-				if _, ok := middlewareFlag[*mp]; !ok {
-					if _, alreadyUse := alreadyUseMiddlewareFlag[*mp]; !alreadyUse {
-						middlewareFlag[*mp] = true
-						switch middleware.(type) {
-						case middlewares.IMiddleWare:
-							controllerHandlers = append(controllerHandlers,
-								middlewares.MiddlewareHandler(middleware.(middlewares.IMiddleWare)))
-						case middlewares.MiddlewareFunc:
-							fn := middleware.(middlewares.MiddlewareFunc)
-							mid := fn()
-							controllerHandlers = append(controllerHandlers, middlewares.MiddlewareHandler(mid))
-						default:
-
-						}
-
-					}
-				}
-			}
-
-			var controllerFlag = make(map[int]bool)
-			for _, controller := range routeDesc.Controller {
-				cp := (*int)(unsafe.Pointer(&controller))
-				if _, ok := controllerFlag[*cp]; !ok {
-					controllerFlag[*cp] = true
-					controllerHandlers = append(controllerHandlers, controllers.ControllerHandler(controller))
-				}
-			}
-
-			process := getFuncByMethod(method)
-			newHandlers := make([]gin.HandlerFunc, 0, len(controllerHandlers)+1)
-			newHandlers = append(newHandlers, addRouterFullPath(group.BasePath(), relativePath))
-			newHandlers = append(newHandlers, controllerHandlers...)
-
-			process(group, relativePath, newHandlers...)
+// registerRoutes registers routes resolved by resolveRoutes on engine.
+func registerRoutes(engine *gin.Engine, routes []RouteInfo, registry *OptionRegistry) {
+	for _, route := range routes {
+		var controllerHandlers []gin.HandlerFunc
+		if route.Desc.Deprecation != nil {
+			controllerHandlers = append(controllerHandlers, route.Desc.Deprecation.handler())
+		}
+		controllerHandlers = append(controllerHandlers, middlewareHandlers(route.middlewares)...)
+		if route.Desc.Options != nil {
+			controllerHandlers = append(controllerHandlers, route.Desc.Options.handlers(registry)...)
+		}
+		for _, controller := range dedupeControllers(route.Desc.Controller) {
+			controllerHandlers = append(controllerHandlers, controllers.ControllerHandler(controller))
 		}
+
+		process := getFuncByMethod(route.Method)
+		newHandlers := middlewareHandlers(route.group.middlewares)
+		newHandlers = append(newHandlers, addRouterFullPath(route.Path))
+		if len(route.checks) > 0 {
+			newHandlers = append(newHandlers, paramChecker(route.checks))
+		}
+		newHandlers = append(newHandlers, controllerHandlers...)
+
+		process(&engine.RouterGroup, route.Path, newHandlers...)
 	}
 }
 
-func CombineRouters(engine *gin.Engine, basePath string, routers ...Router) {
-	v1 := engine.Group(basePath)
-	for _, router := range routers {
-		handleRouter(v1, router)
+// CombineRouters registers routers under basePath, or nothing when it returns
+// an *UnsupportedMiddlewareError or a *RouteConflictError.
+func CombineRouters(engine *gin.Engine, basePath string, routers ...Router) error {
+	return defaultRegistry.CombineRouters(engine, basePath, routers...)
+}
+
+// CombineRouters is the package level CombineRouters using registry.
+func (registry *OptionRegistry) CombineRouters(engine *gin.Engine, basePath string, routers ...Router) error {
+	routes := Describe(basePath, routers...)
+	if err := checkMiddlewares(routers, routes); err != nil {
+		return err
+	}
+	if err := checkRoutes(engine, routes, registry); err != nil {
+		return err
 	}
+	resolveRoutes(routes)
+	registry.registerNames(routes)
+	registerRoutes(engine, routes, registry)
+	registry.record(routes)
+	return nil
 }
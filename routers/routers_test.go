@@ -0,0 +1,66 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/anyufly/gin_common/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+type testRouter struct {
+	name        string
+	config      map[string][]RouteDesc
+	middlewares []interface{}
+}
+
+func (r testRouter) GroupName() string                   { return r.name }
+func (r testRouter) GroupConfig() map[string][]RouteDesc { return r.config }
+func (r testRouter) GroupMiddleware() []interface{}      { return r.middlewares }
+
+func okController(ctx *gin.Context) interface{} {
+	return "ok"
+}
+
+func route(method string) RouteDesc {
+	return RouteDesc{Method: method, Controller: []controllers.ControllerFunc{okController}}
+}
+
+func namedRoute(method string, name string) RouteDesc {
+	desc := route(method)
+	desc.Name = name
+	return desc
+}
+
+func TestCombineRoutersParamsAfterPrefix(t *testing.T) {
+	param := func(ctx *gin.Context) interface{} {
+		ctx.String(http.StatusOK, ctx.Params.ByName(ctx.Query("param")))
+		return nil
+	}
+	engine := gin.New()
+	err := CombineRouters(engine, "", testRouter{config: map[string][]RouteDesc{
+		"/user_:name": {{Method: http.MethodGet, Controller: []controllers.ControllerFunc{param}}},
+		"/a/b:c":      {{Method: http.MethodGet, Controller: []controllers.ControllerFunc{param}}},
+		"/a:b":        {{Method: http.MethodGet, Controller: []controllers.ControllerFunc{param}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"/user_bob?param=name", "bob"},
+		{"/a/b42?param=c", "42"},
+		{"/ax?param=b", "x"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if w.Code != http.StatusOK || w.Body.String() != tt.want {
+			t.Errorf("GET %s answered %d %q, want %q", tt.target, w.Code, w.Body.String(), tt.want)
+		}
+	}
+}
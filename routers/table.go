@@ -0,0 +1,124 @@
+package routers
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/anyufly/gin_common/controllers"
+	"github.com/anyufly/gin_common/middlewares"
+)
+
+// RouteEntry is a row of the route table, without duplicate middlewares.
+type RouteEntry struct {
+	Method           string            `json:"method"`
+	Path             string            `json:"path"`
+	Name             string            `json:"name,omitempty"`
+	Version          string            `json:"version,omitempty"`
+	GroupName        string            `json:"group_name"`
+	Router           string            `json:"router"`
+	GroupMiddlewares []string          `json:"group_middlewares"`
+	Middlewares      []string          `json:"middlewares"`
+	Controllers      []string          `json:"controllers"`
+	Constraints      map[string]string `json:"constraints,omitempty"`
+	Options          *RouteOptions     `json:"options,omitempty"`
+}
+
+// RouteTable returns the routes CombineRouters registers for the same
+// arguments.
+func RouteTable(basePath string, routers ...Router) []RouteEntry {
+	return TableOf(Describe(basePath, routers...))
+}
+
+// TableOf builds the route table of routes. The MiddlewareFunc of routes not
+// returned by OptionRegistry.Routes are called.
+func TableOf(routes []RouteInfo) []RouteEntry {
+	routes = append([]RouteInfo(nil), routes...)
+	resolveRoutes(routes)
+
+	table := make([]RouteEntry, 0, len(routes))
+	for _, route := range routes {
+
+		entry := RouteEntry{
+			Method:           route.Method,
+			Path:             route.Path,
+			Name:             route.Desc.Name,
+			Version:          route.Version,
+			GroupName:        route.GroupName,
+			Router:           reflect.TypeOf(route.Router).String(),
+			GroupMiddlewares: middlewareNames(route.group.middlewares),
+			Middlewares:      middlewareNames(route.middlewares),
+			Controllers:      []string{},
+			Options:          route.Desc.Options,
+		}
+		for param, constraint := range route.Constraints {
+			if entry.Constraints == nil {
+				entry.Constraints = make(map[string]string, len(route.Constraints))
+			}
+			entry.Constraints[param] = constraint.Expr
+		}
+		for _, controller := range dedupeControllers(route.Desc.Controller) {
+			entry.Controllers = append(entry.Controllers, funcName(controller))
+		}
+		table = append(table, entry)
+	}
+	return table
+}
+
+// WriteRouteTable writes table as aligned text columns.
+func WriteRouteTable(w io.Writer, table []RouteEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tNAME\tGROUP\tROUTER\tGROUP MIDDLEWARES\tMIDDLEWARES\tCONTROLLERS\tOPTIONS")
+	for _, entry := range table {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Method,
+			entry.Path,
+			orDash(entry.Name),
+			orDash(entry.GroupName),
+			orDash(entry.Router),
+			orDash(strings.Join(entry.GroupMiddlewares, ", ")),
+			orDash(strings.Join(entry.Middlewares, ", ")),
+			orDash(strings.Join(entry.Controllers, ", ")),
+			orDash(entry.Options.String()),
+		)
+	}
+	return tw.Flush()
+}
+
+func orDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
+
+func middlewareNames(midList []resolvedMiddleware) []string {
+	names := make([]string, 0, len(midList))
+	for _, middleware := range midList {
+		var name string
+		if fn, ok := middleware.source.(middlewares.MiddlewareFunc); ok {
+			name = funcName(fn)
+		} else {
+			name = reflect.TypeOf(middleware.source).String()
+		}
+		if m, ok := middleware.middleware.(middlewares.IdentifiedMiddleWare); ok {
+			name = fmt.Sprintf("%s(%s)", name, m.ID())
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func funcName[F controllers.ControllerFunc | middlewares.MiddlewareFunc](fn F) string {
+	if fn == nil {
+		return "<nil>"
+	}
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "<unknown>"
+	}
+	return f.Name()
+}
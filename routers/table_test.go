@@ -0,0 +1,98 @@
+package routers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/anyufly/gin_common/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func countingRole(name string, calls *int) middlewares.MiddlewareFunc {
+	return func() middlewares.IMiddleWare {
+		*calls++
+		return identifiedMiddleware{testMiddleware{name: name}}
+	}
+}
+
+func TestRouteTable(t *testing.T) {
+	router := testRouter{
+		name: "users",
+		config: map[string][]RouteDesc{
+			"/:id<int>": {namedRoute(http.MethodGet, "user"), route(http.MethodDelete)},
+		},
+		middlewares: []interface{}{identifiedRole("auth"), identifiedRole("auth")},
+	}
+	router.config["/:id<int>"][1].MiddleWare = []interface{}{identifiedRole("auth"), identifiedRole("owner")}
+	router.config["/:id<int>"][1].Options = &RouteOptions{MaxBodyBytes: 10}
+
+	table := RouteTable("/api", router)
+	if len(table) != 2 {
+		t.Fatalf("table %+v", table)
+	}
+	del, get := table[0], table[1]
+	if del.Method != http.MethodDelete || del.Path != "/api/users/:id" || get.Name != "user" || get.GroupName != "users" {
+		t.Errorf("entries %+v %+v", del, get)
+	}
+	if want := []string{"github.com/anyufly/gin_common/routers.identifiedRole.func1(auth)"}; !reflect.DeepEqual(del.GroupMiddlewares, want) {
+		t.Errorf("group middlewares %q, want %q", del.GroupMiddlewares, want)
+	}
+	if want := []string{"github.com/anyufly/gin_common/routers.identifiedRole.func1(owner)"}; !reflect.DeepEqual(del.Middlewares, want) {
+		t.Errorf("route middlewares %q, want %q", del.Middlewares, want)
+	}
+	if want := []string{"github.com/anyufly/gin_common/routers.okController"}; !reflect.DeepEqual(get.Controllers, want) {
+		t.Errorf("controllers %q, want %q", get.Controllers, want)
+	}
+	if get.Constraints["id"] != "int" || del.Options.MaxBodyBytes != 10 {
+		t.Errorf("constraints %v, options %+v", get.Constraints, del.Options)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteRouteTable(&buf, table); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "METHOD") || !strings.Contains(lines[2], " user ") {
+		t.Errorf("text table\n%s", buf.String())
+	}
+}
+
+func TestTableOfCombinedRoutes(t *testing.T) {
+	var groupCalls, routeCalls int
+	router := testRouter{
+		config: map[string][]RouteDesc{
+			"/a": {route(http.MethodGet)},
+			"/b": {route(http.MethodGet), route(http.MethodPost)},
+		},
+		middlewares: []interface{}{countingRole("group", &groupCalls)},
+	}
+	router.config["/a"][0].MiddleWare = []interface{}{countingRole("route", &routeCalls)}
+
+	engine := gin.New()
+	registry := NewOptionRegistry()
+	if err := registry.CombineRouters(engine, "", router); err != nil {
+		t.Fatal(err)
+	}
+	if groupCalls != 1 || routeCalls != 1 {
+		t.Fatalf("factories called %d and %d times by CombineRouters, want once", groupCalls, routeCalls)
+	}
+
+	for i := 0; i < 2; i++ {
+		table := TableOf(registry.Routes())
+		if len(table) != 3 || len(table[0].GroupMiddlewares) != 1 || len(table[0].Middlewares) != 1 {
+			t.Fatalf("table %+v", table)
+		}
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/a", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("GET /a answered %d", w.Code)
+	}
+	if groupCalls != 1 || routeCalls != 1 {
+		t.Errorf("factories called %d and %d times, want once", groupCalls, routeCalls)
+	}
+}
@@ -0,0 +1,117 @@
+package routers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var errOddURLParams = errors.New("routers: URLFor params must be key value pairs")
+
+// defaultRegistry is shared by the package level CombineRouters,
+// CombineVersions and URLFor.
+var defaultRegistry = NewOptionRegistry()
+
+// registerNames records the named routes accepted by checkRoutes.
+func (registry *OptionRegistry) registerNames(routes []RouteInfo) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for _, route := range routes {
+		if route.Desc.Name == "" {
+			continue
+		}
+		registry.names[qualifiedName(route)] = route.Path
+		if route.Version != "" {
+			// versions are combined oldest first, the latest one wins
+			registry.names[route.Desc.Name] = route.Path
+		}
+	}
+}
+
+func qualifiedName(route RouteInfo) string {
+	if route.Version == "" {
+		return route.Desc.Name
+	}
+	return route.Version + "." + route.Desc.Name
+}
+
+func (registry *OptionRegistry) namedPath(name string) (string, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	p, ok := registry.names[name]
+	return p, ok
+}
+
+// URLFor builds the path of the route named name by the package level
+// CombineRouters or CombineVersions. params are key value pairs filling the
+// path parameters, the others form the query string.
+//
+//	routers.URLFor("user", "id", "42", "tab", "posts") // /api/v1/users/42?tab=posts
+func URLFor(name string, params ...string) (string, error) {
+	return defaultRegistry.URLFor(name, params...)
+}
+
+// URLFor is the package level URLFor using registry.
+func (registry *OptionRegistry) URLFor(name string, params ...string) (string, error) {
+	template, ok := registry.namedPath(name)
+	if !ok {
+		return "", fmt.Errorf("routers: no route named %q", name)
+	}
+	if len(params)%2 != 0 {
+		return "", errOddURLParams
+	}
+
+	values := make(map[string][]string, len(params)/2)
+	var keys []string
+	for i := 0; i < len(params); i += 2 {
+		if _, seen := values[params[i]]; !seen {
+			keys = append(keys, params[i])
+		}
+		values[params[i]] = append(values[params[i]], params[i+1])
+	}
+
+	used := make(map[string]bool)
+	segments := strings.Split(template, "/")
+	for i, segment := range segments {
+		prefix, kind, param := splitWildcard(segment)
+		if kind == 0 {
+			continue
+		}
+		value, ok := firstValue(values, param)
+		if !ok {
+			return "", fmt.Errorf("routers: missing parameter %q for route %q", param, name)
+		}
+		used[param] = true
+
+		if kind == ':' {
+			segments[i] = prefix + url.PathEscape(value)
+			continue
+		}
+		parts := strings.Split(strings.TrimPrefix(value, "/"), "/")
+		for j, part := range parts {
+			parts[j] = url.PathEscape(part)
+		}
+		segments[i] = strings.Join(parts, "/")
+	}
+
+	query := url.Values{}
+	for _, key := range keys {
+		if !used[key] {
+			query[key] = values[key]
+		}
+	}
+
+	p := strings.Join(segments, "/")
+	if len(query) > 0 {
+		p += "?" + query.Encode()
+	}
+	return p, nil
+}
+
+func firstValue(values map[string][]string, key string) (string, bool) {
+	if v := values[key]; len(v) > 0 {
+		return v[0], true
+	}
+	return "", false
+}
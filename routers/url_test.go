@@ -0,0 +1,99 @@
+package routers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestURLFor(t *testing.T) {
+	err := CombineRouters(gin.New(), "/api/v1", testRouter{name: "users", config: map[string][]RouteDesc{
+		"/:id<int>":        {namedRoute("GET", "urlfor.user")},
+		"/:id/files/*path": {namedRoute("GET", "urlfor.file")},
+		"/":                {namedRoute("GET", "urlfor.users")},
+		"/user_:name":      {namedRoute("GET", "urlfor.prefixed")},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		params  []string
+		want    string
+		wantErr bool
+	}{
+		{name: "urlfor.user", params: []string{"id", "42"}, want: "/api/v1/users/42"},
+		{name: "urlfor.user", params: []string{"id", "42", "tab", "posts"}, want: "/api/v1/users/42?tab=posts"},
+		{name: "urlfor.user", params: []string{"id", "a b/c"}, want: "/api/v1/users/a%20b%2Fc"},
+		{name: "urlfor.users", params: []string{"tag", "a", "tag", "b"}, want: "/api/v1/users/?tag=a&tag=b"},
+		{name: "urlfor.file", params: []string{"id", "1", "path", "/docs/a b.txt"}, want: "/api/v1/users/1/files/docs/a%20b.txt"},
+		{name: "urlfor.prefixed", params: []string{"name", "bob"}, want: "/api/v1/users/user_bob"},
+		{name: "urlfor.user", wantErr: true},
+		{name: "urlfor.user", params: []string{"id"}, wantErr: true},
+		{name: "urlfor.missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := URLFor(tt.name, tt.params...)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("URLFor(%q, %q) = %q, want an error", tt.name, tt.params, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("URLFor(%q, %q): unexpected error: %v", tt.name, tt.params, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("URLFor(%q, %q) = %q, want %q", tt.name, tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestRegistryNames(t *testing.T) {
+	users := testRouter{name: "users", config: map[string][]RouteDesc{"/:id": {namedRoute("GET", "user")}}}
+	members := testRouter{name: "members", config: map[string][]RouteDesc{"/:id": {namedRoute("GET", "user")}}}
+
+	// registries do not share names
+	first, second := NewOptionRegistry(), NewOptionRegistry()
+	if err := first.CombineRouters(gin.New(), "/a", users); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.CombineRouters(gin.New(), "/b", users); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := second.URLFor("user", "id", "1"); err != nil || p != "/b/users/1" {
+		t.Errorf("second.URLFor = %q, %v", p, err)
+	}
+
+	// the latest version owns the unqualified name
+	versioned := NewOptionRegistry()
+	_, err := versioned.CombineVersions(gin.New(), Versioning{Base: "/api", Versions: []Version{
+		{Name: "v1", Routers: []Router{users}},
+		{Name: "v2", Routers: []Router{members}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"v1.user": "/api/v1/users/1", "user": "/api/v2/members/1"} {
+		if p, err := versioned.URLFor(name, "id", "1"); err != nil || p != want {
+			t.Errorf("URLFor(%q) = %q, %v, want %q", name, p, err, want)
+		}
+	}
+
+	// a versioned route does not take the name of an unversioned one
+	_, err = first.CombineVersions(gin.New(), Versioning{Base: "/api", Versions: []Version{
+		{Name: "v1", Routers: []Router{members}},
+	}})
+	var conflictErr *RouteConflictError
+	if !errors.As(err, &conflictErr) || !strings.Contains(err.Error(), `duplicate route name "user"`) {
+		t.Fatalf("expected a name conflict, got %v", err)
+	}
+	if p, _ := first.URLFor("user", "id", "1"); p != "/a/users/1" {
+		t.Errorf("the conflicting version replaced the name: %q", p)
+	}
+}
@@ -0,0 +1,300 @@
+package routers
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultVersionHeader    = "API-Version"
+	DefaultVersionMediaType = "version"
+)
+
+var errEmptyVersionName = errors.New("routers: version name can not be empty")
+
+// Deprecation marks routes deprecated, they answer with the Deprecation
+// (RFC 9745) and Sunset (RFC 8594) headers.
+type Deprecation struct {
+	// At is when the route was deprecated, zero only flags it.
+	At time.Time
+	// Sunset is when the route stops being served, zero omits the header.
+	Sunset time.Time
+	// Link points to the migration documentation.
+	Link string
+}
+
+func (d *Deprecation) handler() gin.HandlerFunc {
+	deprecation := "true"
+	if !d.At.IsZero() {
+		deprecation = "@" + strconv.FormatInt(d.At.Unix(), 10)
+	}
+	var sunset string
+	if !d.Sunset.IsZero() {
+		sunset = d.Sunset.UTC().Format(http.TimeFormat)
+	}
+	var link string
+	if d.Link != "" {
+		link = fmt.Sprintf(`<%s>; rel="deprecation"`, d.Link)
+	}
+
+	return func(ctx *gin.Context) {
+		header := ctx.Writer.Header()
+		header.Set("Deprecation", deprecation)
+		if sunset != "" {
+			header.Set("Sunset", sunset)
+		}
+		if link != "" {
+			header.Add("Link", link)
+		}
+	}
+}
+
+// Version groups the routers served under the Name path segment.
+type Version struct {
+	Name    string
+	Routers []Router
+	// Deprecation applies to the routes without one.
+	Deprecation *Deprecation
+}
+
+// Versioning lists the versions served under Base, oldest first.
+type Versioning struct {
+	Base     string
+	Versions []Version
+	// Header carries the requested version, DefaultVersionHeader when empty.
+	Header string
+	// MediaTypeParam is the Accept parameter carrying it,
+	// DefaultVersionMediaType when empty.
+	MediaTypeParam string
+}
+
+// DescribeVersions lists the routes CombineVersions registers.
+func DescribeVersions(versioning Versioning) []RouteInfo {
+	var routes []RouteInfo
+	for _, version := range versioning.Versions {
+		for _, route := range Describe(versionBasePath(versioning.Base, version.Name), version.Routers...) {
+			route.Version = version.Name
+			if route.Desc.Deprecation == nil {
+				route.Desc.Deprecation = version.Deprecation
+			}
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+func versionBasePath(basePath string, name string) string {
+	return joinPaths(joinPaths("/", basePath), name)
+}
+
+func checkVersions(versions []Version) error {
+	seen := make(map[string]bool)
+	for _, version := range versions {
+		name := strings.Trim(version.Name, " ")
+		if name == "" {
+			return errEmptyVersionName
+		}
+		if strings.ContainsAny(name, "/:*") {
+			return fmt.Errorf("routers: invalid version name %q", version.Name)
+		}
+		if seen[name] {
+			return fmt.Errorf("routers: duplicate version %q", version.Name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// CombineVersions registers every version under Base/<Name> the way
+// CombineRouters does. Names are registered as "v2.user", and as "user" for
+// the latest version having them.
+func CombineVersions(engine *gin.Engine, versioning Versioning) (*VersionResolver, error) {
+	return defaultRegistry.CombineVersions(engine, versioning)
+}
+
+// CombineVersions is the package level CombineVersions using registry.
+func (registry *OptionRegistry) CombineVersions(engine *gin.Engine, versioning Versioning) (*VersionResolver, error) {
+	if err := checkVersions(versioning.Versions); err != nil {
+		return nil, err
+	}
+
+	var routers []Router
+	for _, version := range versioning.Versions {
+		routers = append(routers, version.Routers...)
+	}
+	routes := DescribeVersions(versioning)
+	if err := checkMiddlewares(routers, routes); err != nil {
+		return nil, err
+	}
+	if err := checkRoutes(engine, routes, registry); err != nil {
+		return nil, err
+	}
+	resolveRoutes(routes)
+	registry.registerNames(routes)
+	registerRoutes(engine, routes, registry)
+	registry.record(routes)
+	return newVersionResolver(versioning, routes), nil
+}
+
+type versionRoute struct {
+	method   string
+	segments []string
+}
+
+// VersionResolver rewrites unversioned paths under Base to the version asked
+// for by the header or the Accept media type, or to the latest older version
+// having the route.
+type VersionResolver struct {
+	base       string
+	header     string
+	mediaParam string
+	names      []string
+	routes     map[string][]versionRoute
+}
+
+func newVersionResolver(versioning Versioning, routes []RouteInfo) *VersionResolver {
+	resolver := &VersionResolver{
+		base:       joinPaths("/", versioning.Base),
+		header:     versioning.Header,
+		mediaParam: versioning.MediaTypeParam,
+		routes:     make(map[string][]versionRoute),
+	}
+	if resolver.header == "" {
+		resolver.header = DefaultVersionHeader
+	}
+	if resolver.mediaParam == "" {
+		resolver.mediaParam = DefaultVersionMediaType
+	}
+	for _, version := range versioning.Versions {
+		resolver.names = append(resolver.names, strings.Trim(version.Name, " "))
+	}
+
+	for _, route := range routes {
+		rel := strings.TrimPrefix(route.Path, versionBasePath(versioning.Base, route.Version))
+		resolver.routes[route.Version] = append(resolver.routes[route.Version], versionRoute{
+			method:   route.Method,
+			segments: strings.Split(rel, "/"),
+		})
+	}
+	return resolver
+}
+
+// Resolve returns the version serving method and the unversioned path p.
+func (resolver *VersionResolver) Resolve(method string, p string, header http.Header) (string, bool) {
+	rel, ok := resolver.relative(p)
+	if !ok {
+		return "", false
+	}
+	if first, _, _ := strings.Cut(strings.TrimPrefix(rel, "/"), "/"); resolver.index(first) >= 0 {
+		// already versioned by its path
+		return "", false
+	}
+
+	latest := len(resolver.names) - 1
+	if requested, ok := resolver.requested(header); ok {
+		latest = resolver.index(requested)
+		if latest < 0 {
+			return "", false
+		}
+	}
+
+	segments := strings.Split(rel, "/")
+	for i := latest; i >= 0; i-- {
+		name := resolver.names[i]
+		for _, route := range resolver.routes[name] {
+			if route.method == method && matchSegments(route.segments, segments) {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Handler wraps next, see VersionResolver.
+func (resolver *VersionResolver) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, ok := resolver.Resolve(r.Method, r.URL.Path, r.Header)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rel, _ := resolver.relative(r.URL.Path)
+		u := *r.URL
+		u.Path = joinPaths(joinPaths(resolver.base, version), rel)
+		u.RawPath = ""
+		rewritten := r.WithContext(r.Context())
+		rewritten.URL = &u
+
+		w.Header().Set(resolver.header, version)
+		next.ServeHTTP(w, rewritten)
+	})
+}
+
+func (resolver *VersionResolver) relative(p string) (string, bool) {
+	if resolver.base == "/" {
+		return p, true
+	}
+	if p == resolver.base {
+		return "/", true
+	}
+	rel := strings.TrimPrefix(p, resolver.base)
+	if rel == p || rel[0] != '/' {
+		return "", false
+	}
+	return rel, true
+}
+
+func (resolver *VersionResolver) index(name string) int {
+	for i, n := range resolver.names {
+		if n == name || strings.TrimPrefix(strings.ToLower(n), "v") == strings.ToLower(name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (resolver *VersionResolver) requested(header http.Header) (string, bool) {
+	if v := strings.TrimSpace(header.Get(resolver.header)); v != "" {
+		return v, true
+	}
+	for _, accept := range header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			_, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+			if v := params[resolver.mediaParam]; v != "" {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+// matchSegments matches path segments against a route template like gin.
+func matchSegments(template []string, segments []string) bool {
+	for i, t := range template {
+		prefix, kind, _ := splitWildcard(t)
+		switch kind {
+		case '*':
+			return true
+		case ':':
+			if i >= len(segments) || len(segments[i]) <= len(prefix) || !strings.HasPrefix(segments[i], prefix) {
+				return false
+			}
+		default:
+			if i >= len(segments) || segments[i] != t {
+				return false
+			}
+		}
+	}
+	return len(template) == len(segments)
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/anyufly/gin_common/loggers"
+	"github.com/anyufly/gin_common/response"
+	"github.com/gin-contrib/pprof"
+	"github.com/gin-gonic/gin"
+)
+
+const adminListenerPrefix = "admin+"
+
+var (
+	errAdminAuthRequired = errors.New("admin server requires Auth or Accounts")
+	errAdminNoListener   = errors.New("admin server requires Addr or Listeners")
+)
+
+type Admin struct {
+	Addr      string
+	Listeners []Listener
+	Auth      gin.HandlerFunc
+	Accounts  gin.Accounts
+	PProf     bool
+	Swagger   bool
+	Health    bool
+	Routes    bool
+	LogLevel  bool
+	Reload    bool
+}
+
+func (opt Admin) Apply(server *Server) error {
+	if opt.Auth == nil && len(opt.Accounts) == 0 {
+		return errAdminAuthRequired
+	}
+	if opt.Addr != "" {
+		opt.Listeners = append([]Listener{parseListener(opt.Addr)}, opt.Listeners...)
+	}
+	if len(opt.Listeners) == 0 {
+		return errAdminNoListener
+	}
+	server.admin = &opt
+	return nil
+}
+
+// engine is built when the server starts so that every other option, health
+// checks included, has already been applied.
+func (opt Admin) engine(server *Server) *gin.Engine {
+	engine := gin.New()
+	_ = engine.SetTrustedProxies(nil)
+
+	if opt.Auth != nil {
+		engine.Use(opt.Auth)
+	} else {
+		engine.Use(gin.BasicAuth(opt.Accounts))
+	}
+
+	if opt.PProf {
+		pprof.Register(engine)
+	}
+	if opt.Swagger {
+		engine.GET("/swagger/*any", server.swaggerHandler())
+	}
+	if opt.Health {
+		health := Health{}
+		if server.health != nil {
+			health = *server.health
+		}
+		health.register(server, engine)
+	}
+	if opt.Routes {
+		engine.GET("/routes", server.routesHandler)
+	}
+	if opt.LogLevel {
+		engine.GET("/loglevel", getLogLevel)
+		engine.PUT("/loglevel", setLogLevel)
+	}
+	if opt.Reload {
+		engine.POST("/reload", server.reloadHandler)
+		engine.PUT("/maintenance", server.maintenanceToggleHandler)
+	}
+	return engine
+}
+
+type logLevel struct {
+	Level string `json:"level" form:"level" binding:"required"`
+}
+
+func getLogLevel(ctx *gin.Context) {
+	response.SuccessWithData(logLevel{Level: loggers.Level()}).Render(ctx)
+}
+
+func setLogLevel(ctx *gin.Context) {
+	var req logLevel
+	if err := ctx.ShouldBind(&req); err != nil {
+		response.ParameterError.WithErr(err).Render(ctx)
+		return
+	}
+	if err := loggers.SetLevel(req.Level); err != nil {
+		response.ParameterError.WithMsg(err.Error()).Render(ctx)
+		return
+	}
+	response.SuccessWithData(req).Render(ctx)
+}
+
+func (server *Server) newAdminServer() *http.Server {
+	if server.admin == nil {
+		return nil
+	}
+	return &http.Server{
+		Handler:           server.admin.engine(server),
+		ReadHeaderTimeout: releaseHTTPServer.ReadHeaderTimeout,
+		IdleTimeout:       releaseHTTPServer.IdleTimeout,
+	}
+}
+
+func (server *Server) openAdminListeners(inherited map[string]Listener) ([]net.Listener, []string, error) {
+	if server.admin == nil {
+		return nil, nil, nil
+	}
+	lns, err := openListeners(server.admin.Listeners, inherited, adminListenerPrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	names := listenerNames(server.admin.Listeners, lns)
+	for i := range names {
+		names[i] = adminListenerPrefix + names[i]
+	}
+	return lns, names, nil
+}
+
+func (server *Server) reloadHandler(ctx *gin.Context) {
+	if err := server.ReloadConfig(); err != nil {
+		response.UnknownError.WithMsg(err.Error()).Render(ctx)
+		return
+	}
+	response.SuccessResponse.Render(ctx)
+}
+
+func (server *Server) maintenanceToggleHandler(ctx *gin.Context) {
+	var req MaintenanceConfig
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.ParameterError.WithErr(err).Render(ctx)
+		return
+	}
+
+	current := Maintenance{}
+	if m := server.runtimeConfig().maintenance; m != nil {
+		current = *m
+	}
+	current.Enabled = req.Enabled
+	if req.Message != "" {
+		current.Message = req.Message
+	}
+	if req.AllowPaths != nil {
+		current.AllowPaths = req.AllowPaths
+	}
+
+	if err := server.Reload(current); err != nil {
+		response.UnknownError.WithMsg(err.Error()).Render(ctx)
+		return
+	}
+	response.SuccessWithData(req).Render(ctx)
+}
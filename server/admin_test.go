@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/anyufly/gin_common/loggers"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminApply(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Admin
+		err  error
+	}{
+		{name: "no auth", opt: Admin{Addr: "127.0.0.1:0"}, err: errAdminAuthRequired},
+		{name: "no listener", opt: Admin{Accounts: gin.Accounts{"admin": "secret"}}, err: errAdminNoListener},
+		{name: "accounts", opt: Admin{Addr: "127.0.0.1:0", Accounts: gin.Accounts{"admin": "secret"}}},
+		{name: "auth handler", opt: Admin{Listeners: []Listener{TCPListener{Addr: "127.0.0.1:0"}}, Auth: func(*gin.Context) {}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewServer(gin.TestMode).WithOption(tt.opt)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestAdminAuth(t *testing.T) {
+	token := func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") != "Bearer admin" {
+			ctx.AbortWithStatus(http.StatusForbidden)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		opt    Admin
+		header string
+		status int
+	}{
+		{name: "no credentials", opt: Admin{Accounts: gin.Accounts{"admin": "secret"}}, status: http.StatusUnauthorized},
+		{name: "wrong password", opt: Admin{Accounts: gin.Accounts{"admin": "secret"}}, header: basicAuth("admin", "guess"), status: http.StatusUnauthorized},
+		{name: "account", opt: Admin{Accounts: gin.Accounts{"admin": "secret"}}, header: basicAuth("admin", "secret"), status: http.StatusOK},
+		{name: "auth handler denies", opt: Admin{Auth: token}, header: basicAuth("admin", "secret"), status: http.StatusForbidden},
+		{name: "auth handler allows", opt: Admin{Auth: token}, header: "Bearer admin", status: http.StatusOK},
+		{
+			name:   "auth handler wins over accounts",
+			opt:    Admin{Auth: token, Accounts: gin.Accounts{"admin": "secret"}},
+			header: basicAuth("admin", "secret"),
+			status: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opt.Addr = "127.0.0.1:0"
+			tt.opt.Routes = true
+			server, err := NewServer(gin.TestMode).WithOption(tt.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			engine := server.admin.engine(server)
+
+			r := httptest.NewRequest(http.MethodGet, "/routes", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d", w.Code, tt.status)
+			}
+
+			// the endpoints are not served by the application
+			w = httptest.NewRecorder()
+			server.Handler().ServeHTTP(w, r)
+			if w.Code != http.StatusNotFound {
+				t.Errorf("application answered /routes with %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestAdminLogLevel(t *testing.T) {
+	level := loggers.Level()
+	t.Cleanup(func() {
+		_ = loggers.SetLevel(level)
+	})
+
+	server, err := NewServer(gin.TestMode).WithOption(Admin{Addr: "127.0.0.1:0", Auth: func(*gin.Context) {}, LogLevel: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := server.admin.engine(server)
+	do := func(method string, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/loglevel", strings.NewReader(body))
+		if body != "" {
+			r.Header.Set("Content-Type", gin.MIMEJSON)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := do(http.MethodPut, `{"level": "error"}`); w.Code != http.StatusOK {
+		t.Fatalf("set level: %d %s", w.Code, w.Body)
+	}
+	if loggers.Level() != "error" {
+		t.Errorf("level %q after the update", loggers.Level())
+	}
+	var got struct {
+		Data logLevel `json:"data"`
+	}
+	if err = json.Unmarshal(do(http.MethodGet, "").Body.Bytes(), &got); err != nil || got.Data.Level != "error" {
+		t.Errorf("get level: %+v %v", got, err)
+	}
+	if w := do(http.MethodPut, `{"level": "loud"}`); w.Code == http.StatusOK {
+		t.Errorf("unknown level accepted: %s", w.Body)
+	}
+	if loggers.Level() != "error" {
+		t.Errorf("level %q after a rejected update", loggers.Level())
+	}
+}
+
+func basicAuth(user, password string) string {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.SetBasicAuth(user, password)
+	return r.Header.Get("Authorization")
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/anyufly/gin_common/openapi"
+	"github.com/anyufly/gin_common/response"
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"     // swagger embed files
+	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
+)
+
+const (
+	defaultAPIDocTitle   = "API"
+	defaultAPIDocVersion = "1.0.0"
+)
+
+// APIDoc sets the info section of the OpenAPI document served under
+// /swagger/ by SwaggerEnable and the admin server.
+type APIDoc struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+func (opt APIDoc) Apply(server *Server) error {
+	server.apiDoc = &opt
+	return nil
+}
+
+func (opt APIDoc) info() openapi.Info {
+	info := openapi.Info{Title: opt.Title, Version: opt.Version, Description: opt.Description}
+	if info.Title == "" {
+		info.Title = defaultAPIDocTitle
+	}
+	if info.Version == "" {
+		info.Version = defaultAPIDocVersion
+	}
+	return info
+}
+
+// APIDocument generates the OpenAPI document of the routers applied so far.
+func (server *Server) APIDocument() *openapi.Document {
+	opt := APIDoc{}
+	if server.apiDoc != nil {
+		opt = *server.apiDoc
+	}
+	return openapi.Generate(opt.info(), server.routeOptions.Routes())
+}
+
+// swaggerHandler serves the generated document as doc.json to the swagger
+// UI, and as openapi.json and openapi.yaml for other tools.
+func (server *Server) swaggerHandler() gin.HandlerFunc {
+	ui := ginSwagger.WrapHandler(swaggerFiles.Handler, func(c *ginSwagger.Config) {
+		c.DefaultModelsExpandDepth = -1
+	})
+
+	return func(ctx *gin.Context) {
+		var (
+			body        []byte
+			err         error
+			contentType string
+		)
+		switch ctx.Param("any") {
+		case "/doc.json", "/openapi.json":
+			body, err = server.APIDocument().JSON()
+			contentType = "application/json; charset=utf-8"
+		case "/openapi.yaml":
+			body, err = server.APIDocument().YAML()
+			contentType = "application/yaml; charset=utf-8"
+		default:
+			ui(ctx)
+			return
+		}
+
+		if err != nil {
+			response.UnknownError.WithErr(err).Render(ctx)
+			return
+		}
+		ctx.Data(http.StatusOK, contentType, body)
+	}
+}
@@ -0,0 +1,432 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+const ConfigEnvPrefix = "GIN_SERVER"
+
+var errUnsupportedConfigFormat = errors.New("unsupported config format, expect .yaml, .yml or .json")
+
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+type CorsConfig struct {
+	AllowOrigins     []string `yaml:"allow_origins" json:"allow_origins"`
+	AllowMethods     []string `yaml:"allow_methods" json:"allow_methods"`
+	AllowHeaders     []string `yaml:"allow_headers" json:"allow_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials" json:"allow_credentials"`
+	MaxAge           int      `yaml:"max_age" json:"max_age"`
+}
+
+type ValidatorsConfig struct {
+	Locale string `yaml:"locale" json:"locale"`
+}
+
+type HTTPServerConfig struct {
+	ReadTimeout       Duration `yaml:"read_timeout" json:"read_timeout"`
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout" json:"read_header_timeout"`
+	WriteTimeout      Duration `yaml:"write_timeout" json:"write_timeout"`
+	IdleTimeout       Duration `yaml:"idle_timeout" json:"idle_timeout"`
+	MaxHeaderBytes    int      `yaml:"max_header_bytes" json:"max_header_bytes"`
+	MaxConns          int      `yaml:"max_conns" json:"max_conns"`
+	DisableKeepAlives bool     `yaml:"disable_keep_alives" json:"disable_keep_alives"`
+}
+
+type TrustedProxiesConfig struct {
+	CIDRs           []string `yaml:"cidrs" json:"cidrs"`
+	Headers         []string `yaml:"headers" json:"headers"`
+	TrustedPlatform string   `yaml:"trusted_platform" json:"trusted_platform"`
+}
+
+type AccessLogConfig struct {
+	SkipPaths []string `yaml:"skip_paths" json:"skip_paths"`
+}
+
+type RateLimitConfig struct {
+	Rate  float64 `yaml:"rate" json:"rate"`
+	Burst int     `yaml:"burst" json:"burst"`
+}
+
+type MaintenanceConfig struct {
+	Enabled    bool     `yaml:"enabled" json:"enabled"`
+	Message    string   `yaml:"message" json:"message"`
+	AllowPaths []string `yaml:"allow_paths" json:"allow_paths"`
+}
+
+type Config struct {
+	Mode            string                `yaml:"mode" json:"mode"`
+	Address         []string              `yaml:"address" json:"address"`
+	ShutdownTimeout Duration              `yaml:"shutdown_timeout" json:"shutdown_timeout"`
+	Cors            *CorsConfig           `yaml:"cors" json:"cors"`
+	PProf           bool                  `yaml:"pprof" json:"pprof"`
+	Swagger         bool                  `yaml:"swagger" json:"swagger"`
+	Validators      *ValidatorsConfig     `yaml:"validators" json:"validators"`
+	HTTP            *HTTPServerConfig     `yaml:"http" json:"http"`
+	TrustedProxies  *TrustedProxiesConfig `yaml:"trusted_proxies" json:"trusted_proxies"`
+	AccessLog       *AccessLogConfig      `yaml:"access_log" json:"access_log"`
+	RateLimit       *RateLimitConfig      `yaml:"rate_limit" json:"rate_limit"`
+	Maintenance     *MaintenanceConfig    `yaml:"maintenance" json:"maintenance"`
+}
+
+// FromConfig reads a YAML or JSON file, applies GIN_SERVER_* environment
+// overrides and returns the equivalent options.
+func FromConfig(path string) ([]Option, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	return config.Options()
+}
+
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = decodeYAMLConfig(data, config)
+	case ".json":
+		err = decodeJSONConfig(data, config)
+	default:
+		return nil, fmt.Errorf("%s: %w", path, errUnsupportedConfigFormat)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err = applyEnvOverrides(reflect.ValueOf(config).Elem(), ConfigEnvPrefix); err != nil {
+		return nil, err
+	}
+	if err = config.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+func decodeYAMLConfig(data []byte, config *Config) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err := decoder.Decode(config)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+func decodeJSONConfig(data []byte, config *Config) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(config)
+}
+
+func applyEnvOverrides(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+		key := prefix + "_" + strings.ToUpper(name)
+		fv := v.Field(i)
+
+		if field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct {
+			if !hasEnvWithPrefix(key + "_") {
+				continue
+			}
+			if fv.IsNil() {
+				fv.Set(reflect.New(field.Type.Elem()))
+			}
+			if err := applyEnvOverrides(fv.Elem(), key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		if err := setEnvValue(fv, value); err != nil {
+			return fmt.Errorf("env %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func hasEnvWithPrefix(prefix string) bool {
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func setEnvValue(fv reflect.Value, value string) error {
+	if fv.Type() == reflect.TypeOf(Duration(0)) {
+		var d Duration
+		if err := d.UnmarshalText([]byte(value)); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		fv.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
+
+func (config *Config) Validate() error {
+	var errs []error
+
+	switch config.Mode {
+	case "", gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+	default:
+		errs = append(errs, fmt.Errorf("mode: unknown mode %q", config.Mode))
+	}
+
+	for i, addr := range config.Address {
+		if strings.TrimSpace(addr) == "" {
+			errs = append(errs, fmt.Errorf("address[%d]: empty address", i))
+		}
+	}
+
+	if config.ShutdownTimeout < 0 {
+		errs = append(errs, errors.New("shutdown_timeout: must not be negative"))
+	}
+
+	if config.Cors != nil {
+		errs = append(errs, validateList("cors.allow_origins", config.Cors.AllowOrigins, true)...)
+		errs = append(errs, validateList("cors.allow_methods", config.Cors.AllowMethods, false)...)
+		errs = append(errs, validateList("cors.allow_headers", config.Cors.AllowHeaders, false)...)
+		if config.Cors.MaxAge < 0 {
+			errs = append(errs, errors.New("cors.max_age: must not be negative"))
+		}
+	}
+
+	if config.Validators != nil && !checkLocaleSupported(config.Validators.Locale) {
+		errs = append(errs, fmt.Errorf("validators.locale: %w: %q", errNotSupportedLocale, config.Validators.Locale))
+	}
+
+	if config.HTTP != nil {
+		if config.HTTP.MaxHeaderBytes < 0 {
+			errs = append(errs, errors.New("http.max_header_bytes: must not be negative"))
+		}
+		if config.HTTP.MaxConns < 0 {
+			errs = append(errs, errors.New("http.max_conns: must not be negative"))
+		}
+	}
+
+	if config.TrustedProxies != nil {
+		if _, err := newClientIPResolver(config.TrustedProxies.option()); err != nil {
+			errs = append(errs, fmt.Errorf("trusted_proxies: %w", err))
+		}
+	}
+
+	if config.RateLimit != nil && (config.RateLimit.Rate < 0 || config.RateLimit.Burst < 0) {
+		errs = append(errs, errors.New("rate_limit: rate and burst must not be negative"))
+	}
+
+	return combineErrors(errs...)
+}
+
+func validateList(name string, items []string, required bool) []error {
+	var errs []error
+	if required && len(items) == 0 {
+		errs = append(errs, fmt.Errorf("%s: at least one value is required", name))
+	}
+	for i, item := range items {
+		if strings.TrimSpace(item) == "" {
+			errs = append(errs, fmt.Errorf("%s[%d]: empty value", name, i))
+		}
+		if strings.Contains(item, ",") {
+			errs = append(errs, fmt.Errorf("%s[%d]: %q must be a single value, use a list instead of commas", name, i, item))
+		}
+	}
+	return errs
+}
+
+func (config TrustedProxiesConfig) option() TrustedProxies {
+	return TrustedProxies{
+		CIDRs:           config.CIDRs,
+		Headers:         config.Headers,
+		TrustedPlatform: config.TrustedPlatform,
+	}
+}
+
+func (config CorsConfig) option() Cors {
+	return Cors{
+		AllowOrigins:     strings.Join(config.AllowOrigins, ","),
+		AllowMethods:     strings.Join(config.AllowMethods, ","),
+		AllowHeaders:     strings.Join(config.AllowHeaders, ","),
+		AllowCredentials: config.AllowCredentials,
+		MaxAge:           config.MaxAge,
+	}
+}
+
+func (config HTTPServerConfig) option() HTTPServer {
+	return HTTPServer{
+		ReadTimeout:       time.Duration(config.ReadTimeout),
+		ReadHeaderTimeout: time.Duration(config.ReadHeaderTimeout),
+		WriteTimeout:      time.Duration(config.WriteTimeout),
+		IdleTimeout:       time.Duration(config.IdleTimeout),
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+		MaxConns:          config.MaxConns,
+		DisableKeepAlives: config.DisableKeepAlives,
+	}
+}
+
+func (config RateLimitConfig) option() RateLimit {
+	return RateLimit{Rate: config.Rate, Burst: config.Burst}
+}
+
+func (config MaintenanceConfig) option() Maintenance {
+	return Maintenance{
+		Enabled:    config.Enabled,
+		Message:    config.Message,
+		AllowPaths: config.AllowPaths,
+	}
+}
+
+// Options returns the options described by the config. The access_log section
+// is only used by ReloadOptions since the logger itself can not be configured
+// from a file, apply an AccessLog option with the logger instead.
+func (config *Config) Options() ([]Option, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	var opts []Option
+	if config.Mode != "" {
+		opts = append(opts, Mode(config.Mode))
+	}
+	if len(config.Address) > 0 {
+		listeners := make(Listeners, 0, len(config.Address))
+		for _, addr := range config.Address {
+			listeners = append(listeners, parseListener(addr))
+		}
+		opts = append(opts, listeners)
+	}
+	if config.ShutdownTimeout > 0 {
+		opts = append(opts, GracefulShutdown{Timeout: time.Duration(config.ShutdownTimeout)})
+	}
+	if config.HTTP != nil {
+		opts = append(opts, config.HTTP.option())
+	}
+	if config.TrustedProxies != nil {
+		opts = append(opts, config.TrustedProxies.option())
+	}
+	if config.Validators != nil {
+		opts = append(opts, Validators{Locale: config.Validators.Locale})
+	}
+	if config.Cors != nil {
+		opts = append(opts, config.Cors.option())
+	}
+	if config.Maintenance != nil {
+		opts = append(opts, config.Maintenance.option())
+	}
+	if config.RateLimit != nil {
+		opts = append(opts, config.RateLimit.option())
+	}
+	if config.PProf {
+		opts = append(opts, PProfEnable(true))
+	}
+	if config.Swagger {
+		opts = append(opts, SwaggerEnable(true))
+	}
+	return opts, nil
+}
+
+// ReloadOptions returns the reloadable options described by the config, see
+// Server.Reload. A missing section gives the zero value of its option.
+func (config *Config) ReloadOptions() []Option {
+	var opts []Option
+
+	cors := Cors{}
+	if config.Cors != nil {
+		cors = config.Cors.option()
+	}
+	opts = append(opts, cors)
+
+	trustedProxies := TrustedProxies{}
+	if config.TrustedProxies != nil {
+		trustedProxies = config.TrustedProxies.option()
+	}
+	opts = append(opts, trustedProxies)
+
+	accessLog := AccessLog{}
+	if config.AccessLog != nil {
+		accessLog.SkipPaths = config.AccessLog.SkipPaths
+	}
+	opts = append(opts, accessLog)
+
+	rateLimit := RateLimit{}
+	if config.RateLimit != nil {
+		rateLimit = config.RateLimit.option()
+	}
+	opts = append(opts, rateLimit)
+
+	maintenance := Maintenance{}
+	if config.Maintenance != nil {
+		maintenance = config.Maintenance.option()
+	}
+	return append(opts, maintenance)
+}
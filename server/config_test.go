@@ -0,0 +1,211 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyEnvOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		config  Config
+		want    Config
+		wantErr string
+	}{
+		{
+			name:   "no env",
+			config: Config{Mode: "debug"},
+			want:   Config{Mode: "debug"},
+		},
+		{
+			name: "scalars",
+			env: map[string]string{
+				"GIN_SERVER_MODE":             "release",
+				"GIN_SERVER_PPROF":            "true",
+				"GIN_SERVER_SHUTDOWN_TIMEOUT": "5s",
+			},
+			config: Config{Mode: "debug"},
+			want:   Config{Mode: "release", PProf: true, ShutdownTimeout: Duration(5 * time.Second)},
+		},
+		{
+			name:   "lists",
+			env:    map[string]string{"GIN_SERVER_ADDRESS": " :8080, ,unix:/tmp/s.sock "},
+			config: Config{Address: []string{":80"}},
+			want:   Config{Address: []string{":8080", "unix:/tmp/s.sock"}},
+		},
+		{
+			name: "nested sections are created",
+			env: map[string]string{
+				"GIN_SERVER_RATE_LIMIT_RATE":       "2.5",
+				"GIN_SERVER_RATE_LIMIT_BURST":      "10",
+				"GIN_SERVER_HTTP_READ_TIMEOUT":     "1m",
+				"GIN_SERVER_CORS_ALLOW_ORIGINS":    "https://a.example,https://b.example",
+				"GIN_SERVER_MAINTENANCE_ENABLED":   "1",
+				"GIN_SERVER_MAINTENANCE_MESSAGE":   "back soon",
+				"GIN_SERVER_TRUSTED_PROXIES_CIDRS": "10.0.0.0/8",
+			},
+			config: Config{Cors: &CorsConfig{MaxAge: 60}},
+			want: Config{
+				Cors:           &CorsConfig{AllowOrigins: []string{"https://a.example", "https://b.example"}, MaxAge: 60},
+				HTTP:           &HTTPServerConfig{ReadTimeout: Duration(time.Minute)},
+				TrustedProxies: &TrustedProxiesConfig{CIDRs: []string{"10.0.0.0/8"}},
+				RateLimit:      &RateLimitConfig{Rate: 2.5, Burst: 10},
+				Maintenance:    &MaintenanceConfig{Enabled: true, Message: "back soon"},
+			},
+		},
+		{
+			name:    "invalid bool",
+			env:     map[string]string{"GIN_SERVER_SWAGGER": "maybe"},
+			wantErr: "env GIN_SERVER_SWAGGER",
+		},
+		{
+			name:    "invalid duration",
+			env:     map[string]string{"GIN_SERVER_HTTP_IDLE_TIMEOUT": "soon"},
+			wantErr: "env GIN_SERVER_HTTP_IDLE_TIMEOUT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			config := tt.config
+			err := applyEnvOverrides(reflect.ValueOf(&config).Elem(), ConfigEnvPrefix)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(config, tt.want) {
+				t.Errorf("config\n got %+v\nwant %+v", config, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		env     map[string]string
+		mode    string
+		wantErr string
+	}{
+		{name: "yaml", path: write("a.yaml", "mode: debug\n"), mode: "debug"},
+		{name: "json", path: write("a.json", `{"mode": "release"}`), mode: "release"},
+		{name: "empty yaml", path: write("empty.yml", ""), mode: ""},
+		{
+			name: "env wins over the file",
+			path: write("b.yaml", "mode: debug\n"),
+			env:  map[string]string{"GIN_SERVER_MODE": "test"},
+			mode: "test",
+		},
+		{name: "unknown field", path: write("c.yaml", "modes: debug\n"), wantErr: "field modes not found"},
+		{name: "invalid mode", path: write("d.yaml", "mode: fast\n"), wantErr: `unknown mode "fast"`},
+		{name: "invalid env mode", path: write("e.yaml", ""), env: map[string]string{"GIN_SERVER_MODE": "fast"}, wantErr: `unknown mode "fast"`},
+		{name: "unsupported format", path: write("f.toml", ""), wantErr: errUnsupportedConfigFormat.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			config, err := LoadConfig(tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if config.Mode != tt.mode {
+				t.Errorf("mode %q, want %q", config.Mode, tt.mode)
+			}
+		})
+	}
+}
+
+func TestConfigOptions(t *testing.T) {
+	config := Config{
+		Mode:            "release",
+		Address:         []string{":8080", "unix:/tmp/s.sock"},
+		ShutdownTimeout: Duration(5 * time.Second),
+		Cors:            &CorsConfig{AllowOrigins: []string{"https://a.example", "https://b.example"}, MaxAge: 60},
+		Swagger:         true,
+		HTTP:            &HTTPServerConfig{ReadTimeout: Duration(time.Minute), MaxConns: 10},
+		AccessLog:       &AccessLogConfig{SkipPaths: []string{"/healthz"}},
+		RateLimit:       &RateLimitConfig{Rate: 2, Burst: 4},
+	}
+	opts, err := config.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Option{
+		Mode("release"),
+		Listeners{TCPListener{Addr: ":8080"}, UnixListener{Path: "/tmp/s.sock"}},
+		GracefulShutdown{Timeout: 5 * time.Second},
+		HTTPServer{ReadTimeout: time.Minute, MaxConns: 10},
+		Cors{AllowOrigins: "https://a.example,https://b.example", MaxAge: 60},
+		RateLimit{Rate: 2, Burst: 4},
+		SwaggerEnable(true),
+	}
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("options\n got %#v\nwant %#v", opts, want)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := Config{
+		Mode:           "fast",
+		Address:        []string{" "},
+		Cors:           &CorsConfig{AllowOrigins: []string{"https://a.example,https://b.example"}, MaxAge: -1},
+		HTTP:           &HTTPServerConfig{MaxConns: -1},
+		TrustedProxies: &TrustedProxiesConfig{CIDRs: []string{"10.0.0.0/33"}},
+		RateLimit:      &RateLimitConfig{Rate: -1},
+	}
+	err := config.Validate()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, problem := range []string{
+		`mode: unknown mode "fast"`,
+		"address[0]: empty address",
+		"cors.allow_origins[0]",
+		"cors.max_age",
+		"http.max_conns",
+		"trusted_proxies:",
+		"rate_limit:",
+	} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("error %q does not report %q", err, problem)
+		}
+	}
+
+	if _, err = config.Options(); err == nil {
+		t.Error("Options accepted an invalid config")
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"errors"
+	"strings"
+)
+
+type multiError []error
+
+func (errs multiError) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (errs multiError) Is(target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (errs multiError) As(target interface{}) bool {
+	for _, err := range errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func combineErrors(errs ...error) error {
+	var combined multiError
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if me, ok := err.(multiError); ok {
+			combined = append(combined, me...)
+			continue
+		}
+		combined = append(combined, err)
+	}
+
+	switch len(combined) {
+	case 0:
+		return nil
+	case 1:
+		return combined[0]
+	default:
+		return combined
+	}
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/anyufly/gin_common/response"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultLivenessPath       = "/healthz"
+	defaultReadinessPath      = "/readyz"
+	defaultHealthCheckTimeout = 3 * time.Second
+)
+
+const (
+	healthStatusUp       = "up"
+	healthStatusDown     = "down"
+	healthStatusDegraded = "degraded"
+	healthStatusDraining = "draining"
+)
+
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type healthCheckerFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (hc healthCheckerFunc) Name() string {
+	return hc.name
+}
+
+func (hc healthCheckerFunc) Check(ctx context.Context) error {
+	return hc.fn(ctx)
+}
+
+func NewHealthChecker(name string, fn func(ctx context.Context) error) HealthChecker {
+	return healthCheckerFunc{name: name, fn: fn}
+}
+
+type HealthCheck struct {
+	Checker  HealthChecker
+	Timeout  time.Duration
+	Critical bool
+}
+
+type healthCheckResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Latency  string `json:"latency"`
+	Error    string `json:"error,omitempty"`
+}
+
+type healthReport struct {
+	Status string              `json:"status"`
+	Checks []healthCheckResult `json:"checks,omitempty"`
+}
+
+type Health struct {
+	LivenessPath  string
+	ReadinessPath string
+	Checks        []HealthCheck
+}
+
+func (opt Health) Apply(server *Server) error {
+	opt.register(server, server.engine)
+	server.health = &opt
+	return nil
+}
+
+func (opt Health) register(server *Server, routes gin.IRoutes) {
+	livenessPath := opt.LivenessPath
+	if livenessPath == "" {
+		livenessPath = defaultLivenessPath
+	}
+	readinessPath := opt.ReadinessPath
+	if readinessPath == "" {
+		readinessPath = defaultReadinessPath
+	}
+
+	routes.GET(livenessPath, opt.liveness)
+	routes.GET(readinessPath, opt.readiness(server))
+}
+
+func (opt Health) liveness(ctx *gin.Context) {
+	response.SuccessWithData(healthReport{Status: healthStatusUp}).Render(ctx)
+}
+
+func (opt Health) readiness(server *Server) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if server.isStopping() {
+			response.ServiceUnavailableError.WithData(healthReport{Status: healthStatusDraining}).Render(ctx)
+			return
+		}
+
+		report := opt.check(ctx.Request.Context())
+		if report.Status == healthStatusDown {
+			response.ServiceUnavailableError.WithData(report).Render(ctx)
+			return
+		}
+		response.SuccessWithData(report).Render(ctx)
+	}
+}
+
+func (opt Health) check(ctx context.Context) healthReport {
+	results := make([]healthCheckResult, len(opt.Checks))
+
+	var wg sync.WaitGroup
+	for i, hc := range opt.Checks {
+		wg.Add(1)
+		go func(i int, hc HealthCheck) {
+			defer wg.Done()
+			results[i] = runHealthCheck(ctx, hc)
+		}(i, hc)
+	}
+	wg.Wait()
+
+	report := healthReport{Status: healthStatusUp, Checks: results}
+	for _, result := range results {
+		if result.Status == healthStatusUp {
+			continue
+		}
+		if result.Critical {
+			report.Status = healthStatusDown
+			break
+		}
+		report.Status = healthStatusDegraded
+	}
+	return report
+}
+
+func runHealthCheck(ctx context.Context, hc HealthCheck) healthCheckResult {
+	timeout := hc.Timeout
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := healthCheckResult{
+		Name:     hc.Checker.Name(),
+		Status:   healthStatusUp,
+		Critical: hc.Critical,
+	}
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- hc.Checker.Check(checkCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-checkCtx.Done():
+		err = checkCtx.Err()
+	}
+	result.Latency = time.Since(start).String()
+
+	if err != nil {
+		result.Status = healthStatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (server *Server) isStopping() bool {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return server.stopping
+}
@@ -0,0 +1,77 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/anyufly/gin_common/server"
+	"github.com/anyufly/gin_common/servertest"
+)
+
+func TestHealth(t *testing.T) {
+	errDown := errors.New("down")
+	check := func(err error) server.HealthChecker {
+		return server.NewHealthChecker("check", func(ctx context.Context) error {
+			return err
+		})
+	}
+	slow := server.NewHealthChecker("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	tests := []struct {
+		name   string
+		checks []server.HealthCheck
+		status int
+		health string
+	}{
+		{name: "no checks", status: http.StatusOK, health: "up"},
+		{
+			name:   "passing checks",
+			checks: []server.HealthCheck{{Checker: check(nil), Critical: true}, {Checker: check(nil)}},
+			status: http.StatusOK,
+			health: "up",
+		},
+		{
+			name:   "failing optional check",
+			checks: []server.HealthCheck{{Checker: check(nil), Critical: true}, {Checker: check(errDown)}},
+			status: http.StatusOK,
+			health: "degraded",
+		},
+		{
+			name:   "failing critical check",
+			checks: []server.HealthCheck{{Checker: check(errDown), Critical: true}, {Checker: check(nil)}},
+			status: http.StatusServiceUnavailable,
+			health: "down",
+		},
+		{
+			name:   "timed out critical check",
+			checks: []server.HealthCheck{{Checker: slow, Critical: true, Timeout: 10 * time.Millisecond}},
+			status: http.StatusServiceUnavailable,
+			health: "down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := servertest.New(t, server.Health{Checks: tt.checks})
+			s.Get("/healthz").ExpectStatus(http.StatusOK).ExpectJSONPath("data.status", "up")
+			s.Get("/readyz").ExpectStatus(tt.status).ExpectJSONPath("data.status", tt.health)
+		})
+	}
+}
+
+func TestHealthDraining(t *testing.T) {
+	s := servertest.New(t, server.Health{LivenessPath: "/live", ReadinessPath: "/ready"})
+	s.Get("/ready").ExpectStatus(http.StatusOK).ExpectJSONPath("data.status", "up")
+
+	if err := s.Server().Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	s.Get("/live").ExpectStatus(http.StatusOK)
+	s.Get("/ready").ExpectStatus(http.StatusServiceUnavailable).ExpectJSONPath("data.status", "draining")
+}
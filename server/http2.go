@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+const h2cShutdownPollInterval = 10 * time.Millisecond
+
+// HTTP2 tunes HTTP/2, Cleartext additionally serves h2c (prior knowledge and
+// Upgrade) next to HTTP/1.1 on the plain listeners.
+type HTTP2 struct {
+	Cleartext                    bool
+	MaxConcurrentStreams         uint32
+	MaxReadFrameSize             uint32
+	MaxUploadBufferPerConnection int32
+	MaxUploadBufferPerStream     int32
+}
+
+func (opt HTTP2) Apply(server *Server) error {
+	server.http2 = &opt
+	return nil
+}
+
+func (opt HTTP2) server(srv *http.Server) *http2.Server {
+	return &http2.Server{
+		MaxConcurrentStreams:         opt.MaxConcurrentStreams,
+		MaxReadFrameSize:             opt.MaxReadFrameSize,
+		MaxUploadBufferPerConnection: opt.MaxUploadBufferPerConnection,
+		MaxUploadBufferPerStream:     opt.MaxUploadBufferPerStream,
+		IdleTimeout:                  srv.IdleTimeout,
+	}
+}
+
+func (server *Server) configureHTTP2(srv *http.Server) (*h2cConns, error) {
+	if server.http2 == nil {
+		return nil, nil
+	}
+	h2s := server.http2.server(srv)
+
+	// ConfigureServer also makes srv.Shutdown send GOAWAY to the h2c
+	// connections, a plain server keeps its TLS settings unset though
+	tlsConfig, tlsNextProto := srv.TLSConfig, srv.TLSNextProto
+	if err := http2.ConfigureServer(srv, h2s); err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil {
+		srv.TLSConfig, srv.TLSNextProto = nil, tlsNextProto
+	}
+
+	if !server.http2.Cleartext {
+		return nil, nil
+	}
+	conns := &h2cConns{conns: make(map[net.Conn]int)}
+	srv.ConnContext = func(ctx context.Context, c net.Conn) context.Context {
+		return context.WithValue(ctx, h2cConnKey{}, c)
+	}
+	srv.Handler = conns.handler(h2c.NewHandler(srv.Handler, h2s))
+	return conns, nil
+}
+
+type h2cConnKey struct{}
+
+// h2cConns tracks the hijacked h2c connections http.Server forgets.
+type h2cConns struct {
+	mu    sync.Mutex
+	conns map[net.Conn]int
+}
+
+func (c *h2cConns) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if conn, ok := r.Context().Value(h2cConnKey{}).(net.Conn); ok {
+			c.add(conn)
+			defer c.remove(conn)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c *h2cConns) add(conn net.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conns[conn]++
+}
+
+func (c *h2cConns) remove(conn net.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conns[conn]--; c.conns[conn] <= 0 {
+		delete(c.conns, conn)
+	}
+}
+
+func (c *h2cConns) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.conns)
+}
+
+// shutdown waits for the connections to drain after GOAWAY, and closes those
+// left when ctx is done.
+func (c *h2cConns) shutdown(ctx context.Context) error {
+	ticker := time.NewTicker(h2cShutdownPollInterval)
+	defer ticker.Stop()
+	for c.len() > 0 {
+		select {
+		case <-ctx.Done():
+			c.mu.Lock()
+			for conn := range c.conns {
+				_ = conn.Close()
+			}
+			c.mu.Unlock()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/http2"
+)
+
+// startH2C starts a server serving h2c and returns its address.
+func startH2C(t *testing.T, server *Server) string {
+	t.Helper()
+
+	ready := make(chan struct{})
+	if _, err := server.WithOption(
+		HTTP2{Cleartext: true},
+		OnReady{Hook: func(ctx context.Context) error {
+			close(ready)
+			return nil
+		}},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- server.Start("127.0.0.1:0")
+	}()
+	select {
+	case <-ready:
+	case err := <-startErr:
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = server.Stop(context.Background())
+		<-startErr
+	})
+	return server.openListeners[0].Addr().String()
+}
+
+func h2cClient() *http.Client {
+	return &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+}
+
+func TestH2C(t *testing.T) {
+	server := NewServer(gin.TestMode)
+	server.Engine().GET("/proto", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.Request.Proto)
+	})
+	addr := startH2C(t, server)
+
+	tests := []struct {
+		name   string
+		client *http.Client
+		proto  string
+	}{
+		{name: "http/1.1", client: &http.Client{Transport: &http.Transport{}}, proto: "HTTP/1.1"},
+		{name: "prior knowledge", client: h2cClient(), proto: "HTTP/2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.client.Get("http://" + addr + "/proto")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.Proto != tt.proto || string(body) != tt.proto {
+				t.Errorf("response %s, served over %s, want %s", resp.Proto, body, tt.proto)
+			}
+			tt.client.CloseIdleConnections()
+		})
+	}
+}
+
+func TestH2CShutdown(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	server := NewServer(gin.TestMode)
+	server.Engine().GET("/slow", func(ctx *gin.Context) {
+		started <- struct{}{}
+		select {
+		case <-release:
+			ctx.String(http.StatusOK, "done")
+		case <-ctx.Request.Context().Done():
+		}
+	})
+	addr := startH2C(t, server)
+	client := h2cClient()
+
+	get := func() <-chan error {
+		done := make(chan error, 1)
+		go func() {
+			resp, err := client.Get("http://" + addr + "/slow")
+			if err == nil {
+				_, err = io.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+			}
+			done <- err
+		}()
+		<-started
+		return done
+	}
+
+	// the stream running when Stop is called completes
+	done := get()
+	time.AfterFunc(50*time.Millisecond, func() {
+		close(release)
+	})
+	if err := server.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("request failed: %v", err)
+	}
+	if n := server.h2cConns.len(); n != 0 {
+		t.Errorf("%d h2c connections left", n)
+	}
+}
+
+func TestH2CShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	server := NewServer(gin.TestMode)
+	server.Engine().GET("/hang", func(ctx *gin.Context) {
+		close(started)
+		<-ctx.Request.Context().Done()
+	})
+	addr := startH2C(t, server)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := h2cClient().Get("http://" + addr + "/hang")
+		done <- err
+	}()
+	<-started
+
+	// the connections still open when the context is done are closed
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := server.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("the hanging request succeeded")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the connection was not closed")
+	}
+}
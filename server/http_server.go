@@ -0,0 +1,141 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HTTPServer tunes the underlying http.Server. Zero values fall back to the
+// release mode defaults below when gin runs in release mode, negative values
+// disable the corresponding timeout.
+type HTTPServer struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	MaxConns          int
+	DisableKeepAlives bool
+}
+
+// WriteTimeout is left unset on purpose: it would cut long downloads and streams.
+var releaseHTTPServer = HTTPServer{
+	ReadTimeout:       60 * time.Second,
+	ReadHeaderTimeout: 10 * time.Second,
+	IdleTimeout:       120 * time.Second,
+	MaxHeaderBytes:    http.DefaultMaxHeaderBytes,
+}
+
+func (opt HTTPServer) Apply(server *Server) error {
+	server.httpConfig = &opt
+	return nil
+}
+
+func (opt HTTPServer) withDefaults(defaults HTTPServer) HTTPServer {
+	if opt.ReadTimeout == 0 {
+		opt.ReadTimeout = defaults.ReadTimeout
+	}
+	if opt.ReadHeaderTimeout == 0 {
+		opt.ReadHeaderTimeout = defaults.ReadHeaderTimeout
+	}
+	if opt.WriteTimeout == 0 {
+		opt.WriteTimeout = defaults.WriteTimeout
+	}
+	if opt.IdleTimeout == 0 {
+		opt.IdleTimeout = defaults.IdleTimeout
+	}
+	if opt.MaxHeaderBytes == 0 {
+		opt.MaxHeaderBytes = defaults.MaxHeaderBytes
+	}
+	if opt.MaxConns == 0 {
+		opt.MaxConns = defaults.MaxConns
+	}
+	return opt
+}
+
+func (server *Server) httpServerConfig() HTTPServer {
+	var config HTTPServer
+	if server.httpConfig != nil {
+		config = *server.httpConfig
+	}
+	if gin.Mode() == gin.ReleaseMode {
+		config = config.withDefaults(releaseHTTPServer)
+	}
+	return config
+}
+
+func (server *Server) newHTTPServer(addr string) (*http.Server, HTTPServer) {
+	config := server.httpServerConfig()
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           server.Handler(),
+		TLSConfig:         server.tlsConfig,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+	srv.SetKeepAlivesEnabled(!config.DisableKeepAlives)
+	return srv, config
+}
+
+// limitListeners caps the number of simultaneously open connections across all
+// listeners of the server.
+func limitListeners(lns []net.Listener, maxConns int) []net.Listener {
+	if maxConns <= 0 {
+		return lns
+	}
+	sem := make(chan struct{}, maxConns)
+	limited := make([]net.Listener, 0, len(lns))
+	for _, ln := range lns {
+		limited = append(limited, &limitListener{Listener: ln, sem: sem, done: make(chan struct{})})
+	}
+	return limited
+}
+
+type limitListener struct {
+	net.Listener
+	sem       chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	select {
+	case l.sem <- struct{}{}:
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		<-l.sem
+		return nil, err
+	}
+	return &limitConn{Conn: conn, release: func() { <-l.sem }}, nil
+}
+
+func (l *limitListener) Close() error {
+	err := l.Listener.Close()
+	l.closeOnce.Do(func() {
+		close(l.done)
+	})
+	return err
+}
+
+type limitConn struct {
+	net.Conn
+	releaseOnce sync.Once
+	release     func()
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.releaseOnce.Do(c.release)
+	return err
+}
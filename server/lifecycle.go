@@ -0,0 +1,215 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultShutdownTimeout = 15 * time.Second
+	defaultHookTimeout     = 10 * time.Second
+)
+
+var defaultShutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+type Hook func(ctx context.Context) error
+
+type lifecycleHook struct {
+	hook    Hook
+	timeout time.Duration
+}
+
+func (lh lifecycleHook) run(ctx context.Context) error {
+	timeout := lh.timeout
+	if timeout <= 0 {
+		timeout = defaultHookTimeout
+	}
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return lh.hook(hookCtx)
+}
+
+type OnStart struct {
+	Hook    Hook
+	Timeout time.Duration
+}
+
+func (opt OnStart) Apply(server *Server) error {
+	if opt.Hook == nil {
+		return errNilHook
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.startHooks = append(server.startHooks, lifecycleHook{hook: opt.Hook, timeout: opt.Timeout})
+	return nil
+}
+
+type OnReady struct {
+	Hook    Hook
+	Timeout time.Duration
+}
+
+func (opt OnReady) Apply(server *Server) error {
+	if opt.Hook == nil {
+		return errNilHook
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.readyHooks = append(server.readyHooks, lifecycleHook{hook: opt.Hook, timeout: opt.Timeout})
+	return nil
+}
+
+type OnShutdown struct {
+	Hook    Hook
+	Timeout time.Duration
+}
+
+func (opt OnShutdown) Apply(server *Server) error {
+	if opt.Hook == nil {
+		return errNilHook
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.shutdownHooks = append(server.shutdownHooks, lifecycleHook{hook: opt.Hook, timeout: opt.Timeout})
+	return nil
+}
+
+// Prepare runs the start and ready hooks of a server serving through Handler
+// instead of Start. Stop runs its shutdown hooks as usual.
+func (server *Server) Prepare() (err error) {
+	server.mu.Lock()
+	startHooks, readyHooks := server.startHooks, server.readyHooks
+	server.mu.Unlock()
+
+	defer func() {
+		if err != nil {
+			err = combineErrors(err, server.runShutdownHooks())
+		}
+	}()
+
+	if err = server.runHooks(context.Background(), startHooks); err != nil {
+		return fmt.Errorf("start hook: %w", err)
+	}
+	if err = server.runHooks(context.Background(), readyHooks); err != nil {
+		return fmt.Errorf("ready hook: %w", err)
+	}
+	return nil
+}
+
+func (server *Server) runHooks(ctx context.Context, hooks []lifecycleHook) error {
+	for _, h := range hooks {
+		if err := h.run(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type GracefulShutdown struct {
+	Timeout time.Duration
+	Signals []os.Signal
+}
+
+func (opt GracefulShutdown) Apply(server *Server) error {
+	if opt.Timeout > 0 {
+		server.shutdownTimeout = opt.Timeout
+	}
+	if len(opt.Signals) > 0 {
+		server.shutdownSignals = opt.Signals
+	}
+	return nil
+}
+
+// RegisterShutdownHook adds a hook run by Stop, with timeout or the default
+// hook timeout when it is not positive.
+func (server *Server) RegisterShutdownHook(hook Hook, timeout time.Duration) {
+	if hook == nil {
+		return
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.shutdownHooks = append(server.shutdownHooks, lifecycleHook{hook: hook, timeout: timeout})
+}
+
+// runShutdownHooks runs the shutdown hooks once, in reverse order, each with
+// its timeout.
+func (server *Server) runShutdownHooks() error {
+	server.mu.Lock()
+	hooks := server.shutdownHooks
+	server.shutdownHooks = nil
+	server.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		errs = append(errs, hooks[i].run(context.Background()))
+	}
+	return combineErrors(errs...)
+}
+
+func (server *Server) Run(ctx context.Context, addr ...string) error {
+	signals := server.shutdownSignals
+	if len(signals) == 0 {
+		signals = defaultShutdownSignals
+	}
+	signalCtx, stop := signal.NotifyContext(ctx, signals...)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Start(addr...)
+	}()
+
+	var restartCh chan os.Signal
+	if server.restart != nil {
+		restartCh = make(chan os.Signal, 1)
+		signal.Notify(restartCh, server.restart.Signals...)
+		defer signal.Stop(restartCh)
+	}
+
+	var startErr error
+	var restartErrs []error
+	stopped := false
+wait:
+	for {
+		select {
+		case startErr = <-serveErr:
+			stopped = true
+			break wait
+		case <-signalCtx.Done():
+			break wait
+		case <-restartCh:
+			err := server.handoff(server.restart.ReadyTimeout)
+			if err == nil {
+				break wait
+			}
+			if server.restart.OnError != nil {
+				server.restart.OnError(err)
+			} else {
+				restartErrs = append(restartErrs, err)
+			}
+		}
+	}
+
+	timeout := server.shutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	stopErr := server.Stop(shutdownCtx)
+	if !stopped {
+		startErr = <-serveErr
+	}
+
+	if errors.Is(startErr, http.ErrServerClosed) {
+		startErr = nil
+	}
+	return combineErrors(append([]error{startErr, stopErr}, restartErrs...)...)
+}
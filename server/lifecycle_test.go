@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestShutdownHooks(t *testing.T) {
+	server := NewServer(gin.TestMode)
+
+	var order []string
+	hook := func(name string) Hook {
+		return func(ctx context.Context) error {
+			if err := ctx.Err(); err != nil {
+				t.Errorf("hook %s got a done context: %v", name, err)
+			}
+			order = append(order, name)
+			return nil
+		}
+	}
+	server.RegisterShutdownHook(hook("first"), 0)
+	server.RegisterShutdownHook(hook("second"), time.Second)
+	server.RegisterShutdownHook(hook("third"), 0)
+
+	// the hooks do not share the context of Stop, which may be spent already
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := server.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"third", "second", "first"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("hooks ran in order %q, want %q", order, want)
+	}
+
+	// hooks run once
+	order = nil
+	if err := server.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 0 {
+		t.Errorf("hooks ran again: %q", order)
+	}
+}
+
+func TestShutdownHookTimeout(t *testing.T) {
+	server := NewServer(gin.TestMode)
+
+	errHook := errors.New("hook failed")
+	server.RegisterShutdownHook(func(ctx context.Context) error {
+		return errHook
+	}, 0)
+	server.RegisterShutdownHook(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, 10*time.Millisecond)
+
+	start := time.Now()
+	err := server.Stop(context.Background())
+	if elapsed := time.Since(start); elapsed > defaultHookTimeout/2 {
+		t.Errorf("Stop took %s", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errHook) {
+		t.Errorf("expected both hook errors, got %v", err)
+	}
+}
+
+func TestRunStopsWithContext(t *testing.T) {
+	server := NewServer(gin.TestMode)
+	stopped := false
+	server.RegisterShutdownHook(func(ctx context.Context) error {
+		stopped = true
+		return nil
+	}, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := server.Run(ctx, "127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	if !stopped {
+		t.Error("shutdown hook did not run")
+	}
+}
+
+type codeError struct {
+	code int
+}
+
+func (err codeError) Error() string {
+	return "code error"
+}
+
+func TestCombineErrors(t *testing.T) {
+	errA := errors.New("a")
+	errB := errors.New("b")
+
+	if err := combineErrors(nil, nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+	if err := combineErrors(nil, errA); err != errA {
+		t.Errorf("expected the single error, got %v", err)
+	}
+
+	err := combineErrors(errA, combineErrors(errB, codeError{code: 3}))
+	if got, want := err.Error(), "a; b; code error"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Error("errors.Is does not find the combined errors")
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Error("errors.Is finds an error that was not combined")
+	}
+	var target codeError
+	if !errors.As(err, &target) || target.code != 3 {
+		t.Errorf("errors.As = %+v", target)
+	}
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	var order []string
+	record := func(name string) Hook {
+		return func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+	ready := make(chan struct{})
+	server, err := NewServer(gin.TestMode).WithOption(
+		OnShutdown{Hook: record("shutdown 1")},
+		OnReady{Hook: record("ready")},
+		OnStart{Hook: record("start 1")},
+		OnStart{Hook: record("start 2")},
+		OnReady{Hook: func(ctx context.Context) error {
+			close(ready)
+			return nil
+		}},
+		OnShutdown{Hook: record("shutdown 2")},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- server.Start("127.0.0.1:0")
+	}()
+	select {
+	case <-ready:
+	case err = <-startErr:
+		t.Fatalf("start: %v", err)
+	}
+	if err = server.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-startErr; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("start returned %v", err)
+	}
+
+	want := []string{"start 1", "start 2", "ready", "shutdown 2", "shutdown 1"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("hooks ran in order %q, want %q", order, want)
+	}
+}
+
+func TestStartHookTimeout(t *testing.T) {
+	released := false
+	server, err := NewServer(gin.TestMode).WithOption(
+		OnStart{Hook: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, Timeout: 10 * time.Millisecond},
+		OnReady{Hook: func(ctx context.Context) error {
+			t.Error("ready hook ran after a failed start hook")
+			return nil
+		}},
+		OnShutdown{Hook: func(ctx context.Context) error {
+			released = true
+			return nil
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a failed start runs the shutdown hooks itself
+	if err = server.Start("127.0.0.1:0"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+	if !released {
+		t.Error("shutdown hooks did not run after the failed start")
+	}
+}
+
+func TestNilHook(t *testing.T) {
+	for _, opt := range []Option{OnStart{}, OnReady{}, OnShutdown{}} {
+		if _, err := NewServer(gin.TestMode).WithOption(opt); !errors.Is(err, errNilHook) {
+			t.Errorf("%T: expected errNilHook, got %v", opt, err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	unixAddressPrefix     = "unix:"
+	systemdListenFdsStart = 3
+)
+
+var errNoSystemdListeners = errors.New("no listeners passed by systemd socket activation")
+
+type Listener interface {
+	Listen() (net.Listener, error)
+	String() string
+}
+
+type TCPListener struct {
+	Addr string
+}
+
+func (l TCPListener) Listen() (net.Listener, error) {
+	return net.Listen("tcp", l.Addr)
+}
+
+func (l TCPListener) String() string {
+	return "tcp:" + l.Addr
+}
+
+type UnixListener struct {
+	Path string
+	Mode os.FileMode
+}
+
+func (l UnixListener) Listen() (net.Listener, error) {
+	if info, err := os.Stat(l.Path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		// remove the socket file left behind by a previous process
+		if err = os.Remove(l.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen("unix", l.Path)
+	if err != nil {
+		return nil, err
+	}
+	if l.Mode != 0 {
+		if err = os.Chmod(l.Path, l.Mode); err != nil {
+			_ = ln.Close()
+			return nil, err
+		}
+	}
+	return ln, nil
+}
+
+func (l UnixListener) String() string {
+	return unixAddressPrefix + l.Path
+}
+
+type FDListener struct {
+	FD   uintptr
+	Name string
+}
+
+func (l FDListener) Listen() (net.Listener, error) {
+	f := os.NewFile(l.FD, l.Name)
+	if f == nil {
+		return nil, fmt.Errorf("invalid listener file descriptor %d", l.FD)
+	}
+	defer f.Close()
+	return net.FileListener(f)
+}
+
+func (l FDListener) close() error {
+	return os.NewFile(l.FD, l.Name).Close()
+}
+
+func (l FDListener) String() string {
+	if l.Name != "" {
+		return fmt.Sprintf("fd:%d(%s)", l.FD, l.Name)
+	}
+	return fmt.Sprintf("fd:%d", l.FD)
+}
+
+type Listeners []Listener
+
+func (opt Listeners) Apply(server *Server) error {
+	server.listeners = append(server.listeners, opt...)
+	return nil
+}
+
+type SystemdActivation struct {
+	Names []string
+}
+
+func (opt SystemdActivation) Apply(server *Server) error {
+	if isRestartChild() {
+		// the sockets are handed off by the parent process instead
+		return nil
+	}
+	listeners, err := systemdListeners(opt.Names)
+	if err != nil {
+		return err
+	}
+	server.listeners = append(server.listeners, listeners...)
+	return nil
+}
+
+func systemdListeners(names []string) ([]Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, errNoSystemdListeners
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, errNoSystemdListeners
+	}
+
+	var fdNames []string
+	if v := os.Getenv("LISTEN_FDNAMES"); v != "" {
+		fdNames = strings.Split(v, ":")
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var listeners []Listener
+	for i := 0; i < count; i++ {
+		l := FDListener{FD: uintptr(systemdListenFdsStart + i)}
+		if i < len(fdNames) {
+			l.Name = fdNames[i]
+		}
+		if len(wanted) > 0 && !wanted[l.Name] {
+			continue
+		}
+		listeners = append(listeners, l)
+	}
+
+	if len(listeners) == 0 {
+		return nil, errNoSystemdListeners
+	}
+
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+	return listeners, nil
+}
+
+func parseListener(addr string) Listener {
+	if strings.HasPrefix(addr, unixAddressPrefix) {
+		return UnixListener{Path: strings.TrimPrefix(addr, unixAddressPrefix)}
+	}
+	return TCPListener{Addr: addr}
+}
+
+func openListeners(listeners []Listener, inherited map[string]Listener, prefix string) ([]net.Listener, error) {
+	opened := make([]net.Listener, 0, len(listeners)+len(inherited))
+	for _, l := range listeners {
+		il, isInherited := inherited[prefix+l.String()]
+		if isInherited {
+			delete(inherited, prefix+l.String())
+			l = il
+		}
+		ln, err := l.Listen()
+		if err != nil {
+			closeListeners(opened)
+			return nil, fmt.Errorf("listen on %s: %w", l, err)
+		}
+		if ul, ok := ln.(*net.UnixListener); ok && isInherited {
+			// take over removing the socket file from the parent
+			ul.SetUnlinkOnClose(true)
+		}
+		opened = append(opened, ln)
+	}
+
+	if prefix != "" {
+		return opened, nil
+	}
+
+	// listeners handed off by the parent that this process was not configured with are still served
+	for name, l := range inherited {
+		if strings.HasPrefix(name, adminListenerPrefix) {
+			_ = l.(FDListener).close()
+			continue
+		}
+		ln, err := l.Listen()
+		if err != nil {
+			closeListeners(opened)
+			return nil, fmt.Errorf("listen on %s: %w", l, err)
+		}
+		opened = append(opened, ln)
+	}
+	return opened, nil
+}
+
+func listenerNames(listeners []Listener, lns []net.Listener) []string {
+	names := make([]string, 0, len(lns))
+	for i, ln := range lns {
+		if i < len(listeners) {
+			names = append(names, listeners[i].String())
+			continue
+		}
+		names = append(names, ln.Addr().Network()+":"+ln.Addr().String())
+	}
+	return names
+}
+
+func closeListeners(lns []net.Listener) {
+	for _, ln := range lns {
+		_ = ln.Close()
+	}
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSystemdListeners(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	tests := []struct {
+		name  string
+		env   map[string]string
+		names []string
+		want  []Listener
+	}{
+		{name: "not activated", env: map[string]string{}},
+		{name: "other process", env: map[string]string{"LISTEN_PID": "1", "LISTEN_FDS": "1"}},
+		{name: "invalid count", env: map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "x"}},
+		{name: "no fds", env: map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "0"}},
+		{
+			name: "unnamed",
+			env:  map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "2"},
+			want: []Listener{FDListener{FD: 3}, FDListener{FD: 4}},
+		},
+		{
+			name: "named",
+			env:  map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "3", "LISTEN_FDNAMES": "http:admin"},
+			want: []Listener{FDListener{FD: 3, Name: "http"}, FDListener{FD: 4, Name: "admin"}, FDListener{FD: 5}},
+		},
+		{
+			name:  "selected by name",
+			env:   map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "3", "LISTEN_FDNAMES": "http:admin:http"},
+			names: []string{"http"},
+			want:  []Listener{FDListener{FD: 3, Name: "http"}, FDListener{FD: 5, Name: "http"}},
+		},
+		{
+			name:  "no listener of the name",
+			env:   map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "1", "LISTEN_FDNAMES": "admin"},
+			names: []string{"http"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			listeners, err := systemdListeners(tt.names)
+			if tt.want == nil {
+				if !errors.Is(err, errNoSystemdListeners) {
+					t.Fatalf("expected errNoSystemdListeners, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(listeners, tt.want) {
+				t.Errorf("listeners %v, want %v", listeners, tt.want)
+			}
+			if v := os.Getenv("LISTEN_FDS"); v != "" {
+				t.Errorf("LISTEN_FDS=%s is passed on to child processes", v)
+			}
+		})
+	}
+}
+
+func TestResolveListeners(t *testing.T) {
+	configured := []Listener{UnixListener{Path: "/tmp/a.sock"}}
+	tests := []struct {
+		name       string
+		configured []Listener
+		addr       []string
+		port       string
+		want       []Listener
+	}{
+		{name: "default", want: []Listener{TCPListener{Addr: ":8080"}}},
+		{name: "port env", port: "9000", want: []Listener{TCPListener{Addr: ":9000"}}},
+		{
+			name: "addresses",
+			addr: []string{":80", "unix:/tmp/b.sock"},
+			port: "9000",
+			want: []Listener{TCPListener{Addr: ":80"}, UnixListener{Path: "/tmp/b.sock"}},
+		},
+		{
+			name:       "configured first",
+			configured: configured,
+			addr:       []string{":80"},
+			want:       []Listener{UnixListener{Path: "/tmp/a.sock"}, TCPListener{Addr: ":80"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PORT", tt.port)
+			if got := resolveListeners(tt.configured, tt.addr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("listeners %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeMultipleListeners(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets")
+	}
+	socket := filepath.Join(t.TempDir(), "s.sock")
+	// a stale socket file does not keep the server from starting
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	ready := make(chan struct{})
+	server, err := NewServer(gin.TestMode).WithOption(
+		Listeners{UnixListener{Path: socket, Mode: 0o660}},
+		OnReady{Hook: func(ctx context.Context) error {
+			close(ready)
+			return nil
+		}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Engine().GET("/", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "ok")
+	})
+
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- server.Start("127.0.0.1:0")
+	}()
+	select {
+	case <-ready:
+	case err = <-startErr:
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = server.Stop(context.Background())
+		<-startErr
+	}()
+
+	if info, err := os.Stat(socket); err != nil || info.Mode().Perm() != 0o660 {
+		t.Errorf("socket file mode: %v %v", info, err)
+	}
+
+	server.mu.Lock()
+	names := server.openListenerNames
+	lns := server.openListeners
+	server.mu.Unlock()
+	if len(lns) != 2 || names[0] != "unix:"+socket || names[1] != "tcp:127.0.0.1:0" {
+		t.Fatalf("listening on %q", names)
+	}
+
+	for _, ln := range lns {
+		network, addr := ln.Addr().Network(), ln.Addr().String()
+		client := &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		}}
+		resp, err := client.Get("http://server/")
+		if err != nil {
+			t.Fatalf("%s: %v", network, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != "ok" {
+			t.Errorf("%s: body %q", network, body)
+		}
+		client.CloseIdleConnections()
+	}
+}
+
+func TestOpenListenersFailure(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+
+	free := TCPListener{Addr: "127.0.0.1:0"}
+	_, err = openListeners([]Listener{free, TCPListener{Addr: busy.Addr().String()}}, nil, "")
+	if want := fmt.Sprintf("listen on tcp:%s", busy.Addr()); err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Fatalf("expected an error for the busy address, got %v", err)
+	}
+}
@@ -10,14 +10,26 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"     // swagger embed files
-	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
 )
 
 type Option interface {
 	Apply(*Server) error
 }
 
+var errUnknownMode = errors.New("unknown gin mode")
+
+type Mode string
+
+func (opt Mode) Apply(server *Server) error {
+	switch string(opt) {
+	case gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+		gin.SetMode(string(opt))
+		return nil
+	default:
+		return errUnknownMode
+	}
+}
+
 type Logger struct {
 	LoggerHandler gin.HandlerFunc
 }
@@ -36,6 +48,7 @@ func (opt Recover) Apply(server *Server) error {
 	return nil
 }
 
+// Cors answers CORS requests, its zero value turns CORS off.
 type Cors struct {
 	AllowOrigins     string
 	AllowMethods     string
@@ -45,7 +58,21 @@ type Cors struct {
 }
 
 func (opt Cors) Apply(server *Server) error {
+	first, err := server.applyReloadable(opt)
+	if err != nil {
+		return err
+	}
+	if first {
+		server.engine.Use(server.corsHandler)
+	}
+	return nil
+}
 
+func (opt Cors) reload(config *runtimeConfig) error {
+	if opt == (Cors{}) {
+		config.cors = nil
+		return nil
+	}
 	allowOrigins := strings.Split(opt.AllowOrigins, ",")
 	allowMethods := strings.Split(opt.AllowMethods, ",")
 	allowHeaders := strings.Split(opt.AllowHeaders, ",")
@@ -58,10 +85,23 @@ func (opt Cors) Apply(server *Server) error {
 		AllowCredentials: opt.AllowCredentials,
 		MaxAge:           maxAge,
 	}
-	server.engine.Use(cors.New(c))
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	config.cors = cors.New(c)
 	return nil
 }
 
+func (server *Server) corsHandler(ctx *gin.Context) {
+	config := requestRuntimeConfig(ctx)
+	if config == nil {
+		config = server.runtimeConfig()
+	}
+	if config.cors != nil {
+		config.cors(ctx)
+	}
+}
+
 type PProfEnable bool
 
 func (opt PProfEnable) Apply(server *Server) error {
@@ -107,17 +147,19 @@ type Routers struct {
 }
 
 func (opt Routers) Apply(server *Server) error {
-	routers.CombineRouters(server.engine, opt.Base, opt.Routers...)
+	if err := server.routeOptions.CombineRouters(server.engine, opt.Base, opt.Routers...); err != nil {
+		return err
+	}
 	return nil
 }
 
+// SwaggerEnable serves the swagger UI with the document generated from the
+// applied Routers, see APIDoc.
 type SwaggerEnable bool
 
 func (opt SwaggerEnable) Apply(server *Server) error {
 	if bool(opt) && (gin.IsDebugging() || gin.Mode() == gin.TestMode) {
-		server.engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, func(c *ginSwagger.Config) {
-			c.DefaultModelsExpandDepth = -1
-		}))
+		server.engine.GET("/swagger/*any", server.swaggerHandler())
 	}
 	return nil
 }
@@ -0,0 +1,44 @@
+package server_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/anyufly/gin_common/controllers"
+	"github.com/anyufly/gin_common/routers"
+	"github.com/anyufly/gin_common/server"
+	"github.com/anyufly/gin_common/servertest"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRoutersRejectConflicts(t *testing.T) {
+	ok := func(ctx *gin.Context) interface{} { return nil }
+	get := []routers.RouteDesc{{Method: http.MethodGet, Controller: []controllers.ControllerFunc{ok}}}
+
+	_, err := server.NewServer(gin.TestMode).WithOption(server.Routers{Routers: []routers.Router{
+		servertest.Router{Routes: map[string][]routers.RouteDesc{"/users/:id": get}},
+		servertest.Router{Routes: map[string][]routers.RouteDesc{"/users/:name": get}},
+	}})
+	var conflictErr *routers.RouteConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("expected a *routers.RouteConflictError, got %v", err)
+	}
+}
+
+func TestURLForPerServer(t *testing.T) {
+	ok := func(ctx *gin.Context) interface{} { return nil }
+	named := func(base string) server.Routers {
+		return server.Routers{Base: base, Routers: []routers.Router{servertest.Router{Name: "users", Routes: map[string][]routers.RouteDesc{
+			"/:id": {{Name: "user", Method: http.MethodGet, Controller: []controllers.ControllerFunc{ok}}},
+		}}}}
+	}
+
+	first := servertest.New(t, named("/a"))
+	second := servertest.New(t, named("/b"))
+	for s, want := range map[*server.Server]string{first.Server(): "/a/users/42", second.Server(): "/b/users/42"} {
+		if p, err := s.URLFor("user", "id", "42"); err != nil || p != want {
+			t.Errorf("URLFor = %q, %v, want %q", p, err, want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Phase decides when WithOption applies an option, lower phases first.
+type Phase int
+
+const (
+	PhaseSetup   Phase = 0
+	PhaseContext Phase = 100
+	PhaseRecover Phase = 200
+	// PhaseCors runs first so that rejected requests carry the CORS headers.
+	PhaseCors       Phase = 250
+	PhaseMiddleware Phase = 300
+	PhaseRoutes     Phase = 400
+)
+
+// PhasedOption declares the phase of an option, options that do not implement
+// it are applied in PhaseMiddleware.
+type PhasedOption interface {
+	Option
+	Phase() Phase
+}
+
+// DependentOption declares the options, by type, the server also needs.
+type DependentOption interface {
+	Option
+	DependsOn() []Option
+}
+
+// ConflictingOption declares the options, by type, the server can not have
+// too.
+type ConflictingOption interface {
+	Option
+	ConflictsWith() []Option
+}
+
+// RepeatableOption marks options that may be applied more than once.
+type RepeatableOption interface {
+	Option
+	Repeatable() bool
+}
+
+// MiddlewareOption declares whether an option installs gin middleware, options
+// without a phase do when they do not implement it.
+type MiddlewareOption interface {
+	Option
+	InstallsMiddleware() bool
+}
+
+func (Mode) Phase() Phase              { return PhaseSetup }
+func (Validators) Phase() Phase        { return PhaseSetup }
+func (Listeners) Phase() Phase         { return PhaseSetup }
+func (SystemdActivation) Phase() Phase { return PhaseSetup }
+func (TLS) Phase() Phase               { return PhaseSetup }
+func (HTTPServer) Phase() Phase        { return PhaseSetup }
+func (HTTP2) Phase() Phase             { return PhaseSetup }
+func (GracefulShutdown) Phase() Phase  { return PhaseSetup }
+func (GracefulRestart) Phase() Phase   { return PhaseSetup }
+func (OnStart) Phase() Phase           { return PhaseSetup }
+func (OnReady) Phase() Phase           { return PhaseSetup }
+func (OnShutdown) Phase() Phase        { return PhaseSetup }
+func (WatchConfig) Phase() Phase       { return PhaseSetup }
+func (Admin) Phase() Phase             { return PhaseSetup }
+func (APIDoc) Phase() Phase            { return PhaseSetup }
+func (Authorizer) Phase() Phase        { return PhaseSetup }
+func (RateLimitClasses) Phase() Phase  { return PhaseSetup }
+func (TrustedProxies) Phase() Phase    { return PhaseContext }
+func (Logger) Phase() Phase            { return PhaseContext }
+func (AccessLog) Phase() Phase         { return PhaseContext }
+func (Recover) Phase() Phase           { return PhaseRecover }
+func (Maintenance) Phase() Phase       { return PhaseMiddleware }
+func (RateLimit) Phase() Phase         { return PhaseMiddleware }
+func (Middlewares) Phase() Phase       { return PhaseMiddleware }
+func (Health) Phase() Phase            { return PhaseRoutes }
+func (PProfEnable) Phase() Phase       { return PhaseRoutes }
+func (SwaggerEnable) Phase() Phase     { return PhaseRoutes }
+func (Routers) Phase() Phase           { return PhaseRoutes }
+func (Versioning) Phase() Phase        { return PhaseRoutes }
+func (Static) Phase() Phase            { return PhaseRoutes }
+func (Cors) Phase() Phase              { return PhaseCors }
+
+func (Listeners) Repeatable() bool   { return true }
+func (OnStart) Repeatable() bool     { return true }
+func (OnReady) Repeatable() bool     { return true }
+func (OnShutdown) Repeatable() bool  { return true }
+func (Middlewares) Repeatable() bool { return true }
+func (Routers) Repeatable() bool     { return true }
+func (Static) Repeatable() bool      { return true }
+
+func (Logger) InstallsMiddleware() bool      { return true }
+func (AccessLog) InstallsMiddleware() bool   { return true }
+func (Recover) InstallsMiddleware() bool     { return true }
+func (Cors) InstallsMiddleware() bool        { return true }
+func (Maintenance) InstallsMiddleware() bool { return true }
+func (RateLimit) InstallsMiddleware() bool   { return true }
+func (Middlewares) InstallsMiddleware() bool { return true }
+
+// DependsOn requires the options the reload endpoints reload.
+func (opt Admin) DependsOn() []Option {
+	if opt.Reload {
+		return []Option{WatchConfig{}, Maintenance{}}
+	}
+	return nil
+}
+
+// ConflictsWith rejects AccessLog, both would log every request.
+func (Logger) ConflictsWith() []Option {
+	return []Option{AccessLog{}}
+}
+
+func optionType(opt Option) reflect.Type {
+	t := reflect.TypeOf(opt)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func optionName(opt Option) string {
+	t := optionType(opt)
+	if t.PkgPath() == reflect.TypeOf(Server{}).PkgPath() {
+		return t.Name()
+	}
+	return t.String()
+}
+
+func optionPhase(opt Option) Phase {
+	if po, ok := opt.(PhasedOption); ok {
+		return po.Phase()
+	}
+	return PhaseMiddleware
+}
+
+func optionInstallsMiddleware(opt Option) bool {
+	if mo, ok := opt.(MiddlewareOption); ok {
+		return mo.InstallsMiddleware()
+	}
+	_, phased := opt.(PhasedOption)
+	return !phased
+}
+
+func optionRepeatable(opt Option) bool {
+	ro, ok := opt.(RepeatableOption)
+	return ok && ro.Repeatable()
+}
+
+// sortOptions orders opts by phase and validates them with the applied ones.
+func (server *Server) sortOptions(opts []Option) ([]Option, error) {
+	sorted := make([]Option, 0, len(opts))
+	for _, opt := range opts {
+		if opt != nil {
+			sorted = append(sorted, opt)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return optionPhase(sorted[i]) < optionPhase(sorted[j])
+	})
+
+	var problems []string
+
+	counts := make(map[reflect.Type]int, len(sorted))
+	var first []Option
+	for _, opt := range sorted {
+		t := optionType(opt)
+		if counts[t] == 0 {
+			first = append(first, opt)
+		}
+		counts[t]++
+	}
+	given := func(opt Option) bool {
+		t := optionType(opt)
+		return counts[t] > 0 || server.applied[t] > 0
+	}
+
+	for _, opt := range first {
+		if optionRepeatable(opt) {
+			continue
+		}
+		t := optionType(opt)
+		if total := counts[t] + server.applied[t]; total > 1 {
+			problems = append(problems, fmt.Sprintf("duplicate option %s (given %d times)", optionName(opt), total))
+		}
+	}
+
+	for _, opt := range sorted {
+		if do, ok := opt.(DependentOption); ok {
+			for _, dep := range do.DependsOn() {
+				if !given(dep) {
+					problems = append(problems, fmt.Sprintf("option %s depends on %s which is missing", optionName(opt), optionName(dep)))
+				}
+			}
+		}
+		if co, ok := opt.(ConflictingOption); ok {
+			for _, other := range co.ConflictsWith() {
+				if given(other) {
+					problems = append(problems, fmt.Sprintf("option %s conflicts with %s", optionName(opt), optionName(other)))
+				}
+			}
+		}
+	}
+	for t := range server.applied {
+		co, ok := reflect.Zero(t).Interface().(ConflictingOption)
+		if !ok || counts[t] > 0 {
+			continue
+		}
+		for _, other := range co.ConflictsWith() {
+			if counts[optionType(other)] > 0 {
+				problems = append(problems, fmt.Sprintf("option %s conflicts with %s", optionName(co), optionName(other)))
+			}
+		}
+	}
+
+	if server.routesApplied {
+		var late []string
+		for _, opt := range first {
+			if optionInstallsMiddleware(opt) {
+				late = append(late, optionName(opt))
+			}
+		}
+		if len(late) > 0 {
+			problems = append(problems, fmt.Sprintf("%s would install middleware after routes were registered "+
+				"and not apply to them, pass them in an earlier WithOption call", strings.Join(late, ", ")))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid options: %s", strings.Join(problems, "; "))
+	}
+	return sorted, nil
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type unphasedOption struct{}
+
+func (unphasedOption) Apply(*Server) error { return nil }
+
+func TestSortOptionsOrder(t *testing.T) {
+	server := NewServer(gin.TestMode)
+	sorted, err := server.sortOptions([]Option{
+		Routers{Base: "/a"},
+		Middlewares{},
+		nil,
+		Cors{},
+		Recover{},
+		Routers{Base: "/b"},
+		TrustedProxies{},
+		Mode(gin.TestMode),
+		unphasedOption{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, opt := range sorted {
+		name := optionName(opt)
+		if r, ok := opt.(Routers); ok {
+			name += r.Base
+		}
+		names = append(names, name)
+	}
+	want := "Mode TrustedProxies Recover Cors Middlewares unphasedOption Routers/a Routers/b"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("sorted options\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestSortOptionsProblems(t *testing.T) {
+	tests := []struct {
+		name          string
+		applied       []Option
+		routesApplied bool
+		opts          []Option
+		problems      []string
+	}{
+		{
+			name: "repeatable options",
+			opts: []Option{Routers{}, Routers{}, Middlewares{}, Middlewares{}, OnStart{}, OnStart{}},
+		},
+		{
+			name:     "duplicate option",
+			opts:     []Option{Mode(gin.TestMode), Recover{}, Mode(gin.TestMode)},
+			problems: []string{"duplicate option Mode (given 2 times)"},
+		},
+		{
+			name:     "duplicate of an applied option",
+			applied:  []Option{Cors{}},
+			opts:     []Option{Cors{}},
+			problems: []string{"duplicate option Cors (given 2 times)"},
+		},
+		{
+			name: "missing dependencies",
+			opts: []Option{Admin{Reload: true}},
+			problems: []string{
+				"option Admin depends on WatchConfig which is missing",
+				"option Admin depends on Maintenance which is missing",
+			},
+		},
+		{
+			name:     "missing dependency",
+			opts:     []Option{Admin{Reload: true}, Maintenance{}},
+			problems: []string{"option Admin depends on WatchConfig which is missing"},
+		},
+		{
+			name:    "dependencies applied earlier",
+			applied: []Option{WatchConfig{}, Maintenance{}},
+			opts:    []Option{Admin{Reload: true}},
+		},
+		{
+			name: "no dependency without reload",
+			opts: []Option{Admin{}},
+		},
+		{
+			name:     "conflict",
+			opts:     []Option{AccessLog{}, Logger{}},
+			problems: []string{"option Logger conflicts with AccessLog"},
+		},
+		{
+			name:     "conflict with an applied option",
+			applied:  []Option{Logger{}},
+			opts:     []Option{&AccessLog{}},
+			problems: []string{"option Logger conflicts with AccessLog"},
+		},
+		{
+			name:          "middleware after routes",
+			routesApplied: true,
+			opts:          []Option{Cors{}, Recover{}, unphasedOption{}, Routers{}},
+			problems:      []string{"Recover, Cors, unphasedOption would install middleware after routes"},
+		},
+		{
+			name:          "handler wrappers and routes after routes",
+			routesApplied: true,
+			opts:          []Option{TrustedProxies{}, Authorizer(nil), Routers{}, Static{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(gin.TestMode)
+			for _, opt := range tt.applied {
+				server.applied[optionType(opt)]++
+			}
+			server.routesApplied = tt.routesApplied
+
+			_, err := server.sortOptions(tt.opts)
+			if len(tt.problems) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, problem := range tt.problems {
+				if !strings.Contains(err.Error(), problem) {
+					t.Errorf("error %q does not contain %q", err, problem)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const forwardedHeader = "Forwarded"
+
+var defaultRemoteIPHeaders = []string{forwardedHeader, "X-Forwarded-For", "X-Real-IP"}
+
+type TrustedProxies struct {
+	CIDRs           []string
+	Headers         []string
+	TrustedPlatform string
+}
+
+func (opt TrustedProxies) Apply(server *Server) error {
+	first, err := server.applyReloadable(opt)
+	if err != nil {
+		return err
+	}
+	if first {
+		server.wrapHandler(server.clientIPHandler)
+	}
+	return nil
+}
+
+func (opt TrustedProxies) reload(config *runtimeConfig) error {
+	resolver, err := newClientIPResolver(opt)
+	if err != nil {
+		return err
+	}
+	config.clientIP = resolver
+	return nil
+}
+
+type clientIPResolver struct {
+	trusted  []*net.IPNet
+	headers  []string
+	platform string
+}
+
+func newClientIPResolver(opt TrustedProxies) (*clientIPResolver, error) {
+	resolver := &clientIPResolver{
+		headers:  opt.Headers,
+		platform: opt.TrustedPlatform,
+	}
+	if len(resolver.headers) == 0 {
+		resolver.headers = defaultRemoteIPHeaders
+	}
+
+	for _, cidr := range opt.CIDRs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", cidr)
+			}
+			if ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		resolver.trusted = append(resolver.trusted, ipNet)
+	}
+	return resolver, nil
+}
+
+func (resolver *clientIPResolver) isTrusted(ip net.IP) bool {
+	for _, ipNet := range resolver.trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (resolver *clientIPResolver) resolve(r *http.Request) string {
+	if resolver.platform != "" {
+		if ip := net.ParseIP(strings.TrimSpace(r.Header.Get(resolver.platform))); ip != nil {
+			return ip.String()
+		}
+	}
+
+	host, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
+	if err != nil {
+		return ""
+	}
+	remoteIP := net.ParseIP(host)
+	if remoteIP == nil {
+		return ""
+	}
+	if !resolver.isTrusted(remoteIP) {
+		return remoteIP.String()
+	}
+
+	for _, header := range resolver.headers {
+		var chain []string
+		if http.CanonicalHeaderKey(header) == forwardedHeader {
+			chain = parseForwardedFor(r.Header.Values(header))
+		} else {
+			chain = parseIPList(r.Header.Values(header))
+		}
+		if ip, ok := resolver.clientFromChain(chain); ok {
+			return ip
+		}
+	}
+	return remoteIP.String()
+}
+
+// clientFromChain walks the proxy chain from the nearest hop and returns the
+// first address that is not a trusted proxy.
+func (resolver *clientIPResolver) clientFromChain(chain []string) (string, bool) {
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip := net.ParseIP(chain[i])
+		if ip == nil {
+			return "", false
+		}
+		if i == 0 || !resolver.isTrusted(ip) {
+			return ip.String(), true
+		}
+	}
+	return "", false
+}
+
+func parseIPList(values []string) []string {
+	var ips []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				ips = append(ips, item)
+			}
+		}
+	}
+	return ips
+}
+
+// parseForwardedFor extracts the for= parameters of RFC 7239 Forwarded headers.
+func parseForwardedFor(values []string) []string {
+	var ips []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(key, "for") {
+					continue
+				}
+				ips = append(ips, forwardedNodeIP(strings.Trim(val, `"`)))
+			}
+		}
+	}
+	return ips
+}
+
+func forwardedNodeIP(node string) string {
+	if strings.HasPrefix(node, "[") {
+		if end := strings.Index(node, "]"); end > 0 {
+			return node[1:end]
+		}
+		return node
+	}
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		return host
+	}
+	return node
+}
+
+// clientIPHandler rewrites the request RemoteAddr to the resolved client ip,
+// the engine trusts no proxy so that ctx.ClientIP() returns it as is. Nothing
+// travels through a header a client could set, serving Engine() directly
+// only loses the resolution.
+func (server *Server) clientIPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		config, _ := r.Context().Value(runtimeConfigKey{}).(*runtimeConfig)
+		if config == nil {
+			config = server.runtimeConfig()
+		}
+		ip := config.clientIP.resolve(r)
+		if ip == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		_, port, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
+		if err != nil {
+			port = "0"
+		}
+		resolved := new(http.Request)
+		*resolved = *r
+		resolved.RemoteAddr = net.JoinHostPort(ip, port)
+		next.ServeHTTP(w, resolved)
+	})
+}
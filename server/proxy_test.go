@@ -0,0 +1,226 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseForwardedFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{name: "empty"},
+		{name: "single", values: []string{"for=192.0.2.60"}, want: []string{"192.0.2.60"}},
+		{
+			name:   "parameters and case",
+			values: []string{"proto=https;For=192.0.2.60;by=203.0.113.43"},
+			want:   []string{"192.0.2.60"},
+		},
+		{
+			name:   "list and repeated headers",
+			values: []string{"for=192.0.2.43, for=198.51.100.17", "for=203.0.113.5"},
+			want:   []string{"192.0.2.43", "198.51.100.17", "203.0.113.5"},
+		},
+		{
+			name:   "quoted ipv6 with port",
+			values: []string{`for="[2001:db8:cafe::17]:4711"`},
+			want:   []string{"2001:db8:cafe::17"},
+		},
+		{name: "quoted ipv4 with port", values: []string{`for="192.0.2.60:8080"`}, want: []string{"192.0.2.60"}},
+		{name: "obfuscated", values: []string{"for=_hidden, for=unknown"}, want: []string{"_hidden", "unknown"}},
+		{name: "no for", values: []string{"proto=http;by=203.0.113.43"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseForwardedFor(tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseForwardedFor(%q) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseIPList(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   []string
+	}{
+		{values: nil, want: nil},
+		{values: []string{"1.2.3.4"}, want: []string{"1.2.3.4"}},
+		{values: []string{" 1.2.3.4 ,10.0.0.1,, "}, want: []string{"1.2.3.4", "10.0.0.1"}},
+		{values: []string{"1.2.3.4", "10.0.0.1, 10.0.0.2"}, want: []string{"1.2.3.4", "10.0.0.1", "10.0.0.2"}},
+	}
+
+	for _, tt := range tests {
+		if got := parseIPList(tt.values); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseIPList(%q) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestClientIPResolver(t *testing.T) {
+	tests := []struct {
+		name       string
+		opt        TrustedProxies
+		remoteAddr string
+		header     http.Header
+		want       string
+	}{
+		{
+			name:       "untrusted remote ignores headers",
+			opt:        TrustedProxies{CIDRs: []string{"10.0.0.0/8"}},
+			remoteAddr: "192.0.2.1:1234",
+			header:     http.Header{"X-Forwarded-For": {"1.2.3.4"}},
+			want:       "192.0.2.1",
+		},
+		{
+			name:       "trusted remote uses x-forwarded-for",
+			opt:        TrustedProxies{CIDRs: []string{"10.0.0.0/8"}},
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Forwarded-For": {"1.2.3.4"}},
+			want:       "1.2.3.4",
+		},
+		{
+			name:       "chain skips trusted hops",
+			opt:        TrustedProxies{CIDRs: []string{"10.0.0.0/8"}},
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Forwarded-For": {"6.6.6.6, 1.2.3.4, 10.0.0.2"}},
+			want:       "1.2.3.4",
+		},
+		{
+			name:       "all hops trusted returns the first",
+			opt:        TrustedProxies{CIDRs: []string{"10.0.0.0/8"}},
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}},
+			want:       "10.0.0.3",
+		},
+		{
+			name:       "forwarded header comes first",
+			opt:        TrustedProxies{CIDRs: []string{"10.0.0.1"}},
+			remoteAddr: "10.0.0.1:1234",
+			header: http.Header{
+				"Forwarded":       {`for="[2001:db8::1]:80"`},
+				"X-Forwarded-For": {"1.2.3.4"},
+			},
+			want: "2001:db8::1",
+		},
+		{
+			name:       "invalid chain falls back to the next header",
+			opt:        TrustedProxies{CIDRs: []string{"10.0.0.1"}},
+			remoteAddr: "10.0.0.1:1234",
+			header: http.Header{
+				"X-Forwarded-For": {"not-an-ip"},
+				"X-Real-Ip":       {"1.2.3.4"},
+			},
+			want: "1.2.3.4",
+		},
+		{
+			name:       "configured headers only",
+			opt:        TrustedProxies{CIDRs: []string{"10.0.0.1"}, Headers: []string{"X-Real-IP"}},
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Forwarded-For": {"1.2.3.4"}},
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "trusted platform",
+			opt:        TrustedProxies{TrustedPlatform: "CF-Connecting-IP"},
+			remoteAddr: "192.0.2.1:1234",
+			header:     http.Header{"Cf-Connecting-Ip": {"1.2.3.4"}},
+			want:       "1.2.3.4",
+		},
+		{
+			name:       "invalid trusted platform value",
+			opt:        TrustedProxies{TrustedPlatform: "CF-Connecting-IP"},
+			remoteAddr: "192.0.2.1:1234",
+			header:     http.Header{"Cf-Connecting-Ip": {"1.2.3.4, 5.6.7.8"}},
+			want:       "192.0.2.1",
+		},
+		{
+			name:       "unparsable remote address",
+			remoteAddr: "pipe",
+			want:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, err := newClientIPResolver(tt.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := &http.Request{RemoteAddr: tt.remoteAddr, Header: tt.header}
+			if r.Header == nil {
+				r.Header = http.Header{}
+			}
+			if got := resolver.resolve(r); got != tt.want {
+				t.Errorf("resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientIPResolverInvalidCIDR(t *testing.T) {
+	for _, cidr := range []string{"10.0.0.0/33", "not-an-ip", "10.0.0"} {
+		if _, err := newClientIPResolver(TrustedProxies{CIDRs: []string{cidr}}); err == nil {
+			t.Errorf("expected an error for %q", cidr)
+		}
+	}
+}
+
+func TestTrustedProxiesClientIP(t *testing.T) {
+	server, err := NewServer(gin.TestMode).WithOption(TrustedProxies{CIDRs: []string{"10.0.0.0/8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Engine().GET("/ip", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.ClientIP())
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     http.Header
+		want       string
+	}{
+		{name: "direct", remoteAddr: "192.0.2.1:1234", want: "192.0.2.1"},
+		{
+			name:       "spoofed by an untrusted client",
+			remoteAddr: "192.0.2.1:1234",
+			header:     http.Header{"X-Forwarded-For": {"1.2.3.4"}},
+			want:       "192.0.2.1",
+		},
+		{
+			name:       "through a trusted proxy",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"X-Forwarded-For": {"6.6.6.6, 1.2.3.4"}},
+			want:       "1.2.3.4",
+		},
+		{
+			name:       "forwarded header",
+			remoteAddr: "10.0.0.1:1234",
+			header:     http.Header{"Forwarded": {`for="[2001:db8::1]:4711"`}},
+			want:       "2001:db8::1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for key, values := range tt.header {
+				r.Header[key] = values
+			}
+			w := httptest.NewRecorder()
+			server.Handler().ServeHTTP(w, r)
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("client ip %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/anyufly/gin_common/loggers"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultWatchInterval = 5 * time.Second
+
+var (
+	errNoConfigPath    = errors.New("no config file to reload, use the WatchConfig option")
+	errRestartRequired = errors.New("not applied when the server started, restart it to apply")
+)
+
+// reloadableOption is implemented by the options Server.Reload swaps.
+type reloadableOption interface {
+	Option
+	reload(config *runtimeConfig) error
+}
+
+// runtimeConfig holds everything that can be reloaded, a reload stores a
+// modified copy.
+type runtimeConfig struct {
+	cors         gin.HandlerFunc
+	clientIP     *clientIPResolver
+	accessLogger loggers.GinLogger
+	accessLog    gin.HandlerFunc
+	limiter      *rateLimiter
+	maintenance  *Maintenance
+}
+
+type runtimeConfigKey struct{}
+
+func (server *Server) runtimeConfig() *runtimeConfig {
+	return server.runtime.Load()
+}
+
+func requestRuntimeConfig(ctx *gin.Context) *runtimeConfig {
+	config, _ := ctx.Request.Context().Value(runtimeConfigKey{}).(*runtimeConfig)
+	return config
+}
+
+// updateRuntimeConfig changes the live config the way a reload does.
+func (server *Server) updateRuntimeConfig(update func(config *runtimeConfig) error) error {
+	server.runtimeMu.Lock()
+	defer server.runtimeMu.Unlock()
+
+	config := *server.runtime.Load()
+	if err := update(&config); err != nil {
+		return err
+	}
+	server.runtime.Store(&config)
+	return nil
+}
+
+// Reload swaps the given options in one step, or none if one fails.
+func (server *Server) Reload(opts ...Option) error {
+	return server.updateRuntimeConfig(func(config *runtimeConfig) error {
+		for _, opt := range opts {
+			ro, ok := opt.(reloadableOption)
+			if !ok {
+				return fmt.Errorf("option %s is not reloadable", reflect.TypeOf(opt))
+			}
+			if !server.reloadable[reflect.TypeOf(opt)] {
+				return fmt.Errorf("option %s was never applied to the server", reflect.TypeOf(opt))
+			}
+			if err := ro.reload(config); err != nil {
+				return fmt.Errorf("reload %s: %w", reflect.TypeOf(opt), err)
+			}
+		}
+		return nil
+	})
+}
+
+func (server *Server) runtimeHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), runtimeConfigKey{}, server.runtime.Load())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// applyReloadable stores opt in the live config, first reports whether its
+// middleware still has to be installed.
+func (server *Server) applyReloadable(opt reloadableOption) (first bool, err error) {
+	if err = server.updateRuntimeConfig(opt.reload); err != nil {
+		return false, err
+	}
+	t := reflect.TypeOf(opt)
+	if server.reloadable[t] {
+		return false, nil
+	}
+	server.reloadable[t] = true
+	return true, nil
+}
+
+// WatchConfig polls a config file loaded with FromConfig and reloads its
+// reloadable sections when the file changes.
+type WatchConfig struct {
+	Path     string
+	Interval time.Duration
+	OnError  func(err error)
+}
+
+func (opt WatchConfig) Apply(server *Server) error {
+	if opt.Interval <= 0 {
+		opt.Interval = defaultWatchInterval
+	}
+	info, err := os.Stat(opt.Path)
+	if err != nil {
+		return err
+	}
+	server.configPath = opt.Path
+
+	stop := make(chan struct{})
+	if err = (OnStart{Hook: func(ctx context.Context) error {
+		go opt.watch(server, info.ModTime(), stop)
+		return nil
+	}}).Apply(server); err != nil {
+		return err
+	}
+	server.RegisterShutdownHook(func(ctx context.Context) error {
+		close(stop)
+		return nil
+	}, 0)
+	return nil
+}
+
+func (opt WatchConfig) watch(server *Server, modTime time.Time, stop <-chan struct{}) {
+	ticker := time.NewTicker(opt.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(opt.Path)
+		if err != nil || !info.ModTime().After(modTime) {
+			continue
+		}
+		modTime = info.ModTime()
+
+		if err = server.ReloadConfig(); err != nil && opt.OnError != nil {
+			opt.OnError(err)
+		}
+	}
+}
+
+// ReloadConfig reloads the reloadable sections of the watched config file, a
+// removed section turns its option off. Sections whose option was not applied
+// at start are reported in the error.
+func (server *Server) ReloadConfig() error {
+	if server.configPath == "" {
+		return errNoConfigPath
+	}
+	config, err := LoadConfig(server.configPath)
+	if err != nil {
+		return err
+	}
+
+	var opts []Option
+	var added []string
+	for _, opt := range config.ReloadOptions() {
+		switch {
+		case server.reloadable[reflect.TypeOf(opt)]:
+			opts = append(opts, opt)
+		case !reflect.ValueOf(opt).IsZero():
+			added = append(added, optionName(opt))
+		}
+	}
+	if err = server.Reload(opts...); err != nil {
+		return err
+	}
+	if len(added) > 0 {
+		return fmt.Errorf("options %s: %w", strings.Join(added, ", "), errRestartRequired)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package server_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anyufly/gin_common/controllers"
+	"github.com/anyufly/gin_common/response"
+	"github.com/anyufly/gin_common/routers"
+	"github.com/anyufly/gin_common/server"
+	"github.com/anyufly/gin_common/servertest"
+	"github.com/gin-gonic/gin"
+)
+
+func okRoutes(paths ...string) server.Routers {
+	ok := func(ctx *gin.Context) interface{} {
+		return response.SuccessWithData("ok")
+	}
+	routes := make(map[string][]routers.RouteDesc, len(paths))
+	for _, path := range paths {
+		routes[path] = []routers.RouteDesc{{Method: http.MethodGet, Controller: []controllers.ControllerFunc{ok}}}
+	}
+	return server.Routers{Routers: []routers.Router{servertest.Router{Routes: routes}}}
+}
+
+func TestReload(t *testing.T) {
+	logs := servertest.NewLogRecorder()
+	s := servertest.New(t,
+		server.Cors{AllowOrigins: "https://a.example"},
+		server.RateLimit{Rate: 0.001, Burst: 1},
+		server.AccessLog{Logger: logs, SkipPaths: []string{"/health"}},
+		okRoutes("/ok", "/health"),
+	)
+
+	s.Get("/ok").WithHeader("Origin", "https://a.example").ExpectStatus(http.StatusOK).
+		ExpectHeader("Access-Control-Allow-Origin", "https://a.example")
+	s.Get("/health").ExpectStatus(http.StatusTooManyRequests)
+	if entries := logs.Entries(); len(entries) != 1 {
+		t.Fatalf("expected 1 access log entry, got %d: %+v", len(entries), entries)
+	}
+
+	logs.Reset()
+	if err := s.Server().Reload(
+		server.Cors{AllowOrigins: "https://b.example"},
+		server.RateLimit{},
+		server.AccessLog{SkipPaths: []string{"/ok"}},
+	); err != nil {
+		t.Fatal(err)
+	}
+	s.Get("/ok").WithHeader("Origin", "https://a.example").ExpectStatus(http.StatusForbidden)
+	s.Get("/ok").WithHeader("Origin", "https://b.example").ExpectStatus(http.StatusOK).
+		ExpectHeader("Access-Control-Allow-Origin", "https://b.example")
+	s.Get("/health").ExpectStatus(http.StatusOK)
+	if entries := logs.Entries(); len(entries) != 1 {
+		t.Fatalf("expected 1 access log entry after the reload, got %d: %+v", len(entries), entries)
+	}
+
+	// a failing option keeps the previous config
+	if err := s.Server().Reload(server.RateLimit{Rate: 0.001, Burst: 1}, server.RateLimit{Rate: -1}); err == nil {
+		t.Fatal("expected an error")
+	}
+	s.Get("/ok").ExpectStatus(http.StatusOK)
+	s.Get("/ok").ExpectStatus(http.StatusOK)
+
+	if err := s.Server().Reload(server.Maintenance{Enabled: true}); err == nil {
+		t.Error("reloaded an option that was never applied")
+	}
+	if err := s.Server().Reload(server.Mode(gin.TestMode)); err == nil {
+		t.Error("reloaded an option that is not reloadable")
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`
+cors:
+  allow_origins: ["https://a.example"]
+rate_limit:
+  rate: 0.001
+  burst: 1
+`)
+	opts, err := server.FromConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts = append(opts, server.WatchConfig{Path: path, Interval: time.Hour}, okRoutes("/ok"))
+	s := servertest.New(t, opts...)
+
+	s.Get("/ok").WithHeader("Origin", "https://a.example").ExpectStatus(http.StatusOK).
+		ExpectHeader("Access-Control-Allow-Origin", "https://a.example")
+	s.Get("/ok").ExpectStatus(http.StatusTooManyRequests)
+
+	// a removed section turns its option off, an added one needs a restart
+	write(`
+cors:
+  allow_origins: ["https://b.example"]
+maintenance:
+  enabled: true
+`)
+	err = s.Server().ReloadConfig()
+	if err == nil || !strings.Contains(err.Error(), "Maintenance") || !strings.Contains(err.Error(), "restart") {
+		t.Fatalf("expected an error about the added maintenance section, got %v", err)
+	}
+	s.Get("/ok").WithHeader("Origin", "https://b.example").ExpectStatus(http.StatusOK).
+		ExpectHeader("Access-Control-Allow-Origin", "https://b.example")
+	s.Get("/ok").ExpectStatus(http.StatusOK)
+
+	write("mode: test\n")
+	if err = s.Server().ReloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	s.Get("/ok").WithHeader("Origin", "https://a.example").ExpectStatus(http.StatusOK).
+		ExpectHeader("Access-Control-Allow-Origin", "")
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envListenFds     = "GIN_COMMON_LISTEN_FDS"
+	envListenFdNames = "GIN_COMMON_LISTEN_FDNAMES"
+	envReadyFd       = "GIN_COMMON_READY_FD"
+
+	inheritedFdsStart          = 3
+	defaultRestartReadyTimeout = 30 * time.Second
+)
+
+var (
+	errServerNotServing  = errors.New("server is not serving, nothing to hand off")
+	errChildExited       = errors.New("child process exited before it was ready")
+	errChildReadyTimeout = errors.New("timed out waiting for child process to be ready")
+)
+
+type GracefulRestart struct {
+	Signals      []os.Signal
+	ReadyTimeout time.Duration
+	OnError      func(err error)
+}
+
+func (opt GracefulRestart) Apply(server *Server) error {
+	if len(opt.Signals) == 0 {
+		if len(defaultRestartSignals) == 0 {
+			return errRestartNotSupported
+		}
+		opt.Signals = defaultRestartSignals
+	}
+	if opt.ReadyTimeout <= 0 {
+		opt.ReadyTimeout = defaultRestartReadyTimeout
+	}
+	server.restart = &opt
+	return nil
+}
+
+func isRestartChild() bool {
+	return os.Getenv(envListenFds) != ""
+}
+
+func inheritedListeners() map[string]Listener {
+	count, err := strconv.Atoi(os.Getenv(envListenFds))
+	if err != nil || count <= 0 {
+		return nil
+	}
+	names := strings.Split(os.Getenv(envListenFdNames), ",")
+
+	inherited := make(map[string]Listener, count)
+	for i := 0; i < count; i++ {
+		name := strconv.Itoa(i)
+		if i < len(names) {
+			if n, err := url.QueryUnescape(names[i]); err == nil && n != "" {
+				name = n
+			}
+		}
+		inherited[name] = FDListener{FD: uintptr(inheritedFdsStart + i), Name: name}
+	}
+
+	_ = os.Unsetenv(envListenFds)
+	_ = os.Unsetenv(envListenFdNames)
+	return inherited
+}
+
+func notifyParentReady() error {
+	v := os.Getenv(envReadyFd)
+	if v == "" {
+		return nil
+	}
+	_ = os.Unsetenv(envReadyFd)
+
+	fd, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", envReadyFd, err)
+	}
+	f := os.NewFile(uintptr(fd), "ready")
+	if f == nil {
+		return fmt.Errorf("invalid %s: %d", envReadyFd, fd)
+	}
+	defer f.Close()
+	_, err = f.Write([]byte{1})
+	return err
+}
+
+func childEnv(names []string, readyFd int) []string {
+	env := make([]string, 0, len(os.Environ())+3)
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, envListenFds+"=") ||
+			strings.HasPrefix(kv, envListenFdNames+"=") ||
+			strings.HasPrefix(kv, envReadyFd+"=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+
+	escaped := make([]string, 0, len(names))
+	for _, name := range names {
+		escaped = append(escaped, url.QueryEscape(name))
+	}
+	return append(env,
+		fmt.Sprintf("%s=%d", envListenFds, len(names)),
+		fmt.Sprintf("%s=%s", envListenFdNames, strings.Join(escaped, ",")),
+		fmt.Sprintf("%s=%d", envReadyFd, readyFd),
+	)
+}
+
+type filer interface {
+	File() (*os.File, error)
+}
+
+func listenerFiles(lns []net.Listener) ([]*os.File, error) {
+	files := make([]*os.File, 0, len(lns))
+	for _, ln := range lns {
+		fl, ok := ln.(filer)
+		if !ok {
+			closeFiles(files)
+			return nil, fmt.Errorf("listener %s can not be handed off", ln.Addr())
+		}
+		f, err := fl.File()
+		if err != nil {
+			closeFiles(files)
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		_ = f.Close()
+	}
+}
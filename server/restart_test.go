@@ -0,0 +1,64 @@
+package server
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChildEnvRoundTrip(t *testing.T) {
+	t.Setenv(envListenFds, "9")
+	t.Setenv(envReadyFd, "9")
+
+	names := []string{"tcp::8080", "unix:/tmp/a,b.sock", "admin+tcp:127.0.0.1:9%"}
+	env := childEnv(names, 6)
+
+	var listenFds []string
+	for _, kv := range env {
+		if strings.HasPrefix(kv, envListenFds+"=") || strings.HasPrefix(kv, envReadyFd+"=") {
+			listenFds = append(listenFds, kv)
+		}
+	}
+	if want := []string{envListenFds + "=3", envReadyFd + "=6"}; !reflect.DeepEqual(listenFds, want) {
+		t.Fatalf("child env %q, want %q", listenFds, want)
+	}
+
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		if key == envListenFds || key == envListenFdNames {
+			t.Setenv(key, value)
+		}
+	}
+	if !isRestartChild() {
+		t.Fatal("not a restart child with the child env")
+	}
+	want := map[string]Listener{
+		names[0]: FDListener{FD: 3, Name: names[0]},
+		names[1]: FDListener{FD: 4, Name: names[1]},
+		names[2]: FDListener{FD: 5, Name: names[2]},
+	}
+	if got := inheritedListeners(); !reflect.DeepEqual(got, want) {
+		t.Errorf("inherited listeners %v, want %v", got, want)
+	}
+	if os.Getenv(envListenFds) != "" || os.Getenv(envListenFdNames) != "" {
+		t.Error("the listener env is passed on to child processes")
+	}
+}
+
+func TestInheritedListenersWithoutNames(t *testing.T) {
+	t.Setenv(envListenFds, "2")
+	t.Setenv(envListenFdNames, "")
+	want := map[string]Listener{
+		"0": FDListener{FD: 3, Name: "0"},
+		"1": FDListener{FD: 4, Name: "1"},
+	}
+	if got := inheritedListeners(); !reflect.DeepEqual(got, want) {
+		t.Errorf("inherited listeners %v, want %v", got, want)
+	}
+
+	t.Setenv(envListenFds, "")
+	if got := inheritedListeners(); got != nil {
+		t.Errorf("inherited listeners %v without the env", got)
+	}
+}
@@ -0,0 +1,88 @@
+//go:build !windows
+
+package server
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+var errRestartNotSupported error
+
+var defaultRestartSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR2}
+
+func (server *Server) handoff(readyTimeout time.Duration) error {
+	server.mu.Lock()
+	lns, names := server.openListeners, server.openListenerNames
+	server.mu.Unlock()
+
+	if len(lns) == 0 {
+		return errServerNotServing
+	}
+
+	files, err := listenerFiles(lns)
+	if err != nil {
+		return err
+	}
+	defer closeFiles(files)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		_ = w.Close()
+		return err
+	}
+
+	procFiles := append([]*os.File{os.Stdin, os.Stdout, os.Stderr}, files...)
+	procFiles = append(procFiles, w)
+	proc, err := os.StartProcess(exe, os.Args, &os.ProcAttr{
+		Env:   childEnv(names, inheritedFdsStart+len(files)),
+		Files: procFiles,
+	})
+	_ = w.Close()
+	if err != nil {
+		return err
+	}
+
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		if _, err := r.Read(buf); err != nil {
+			ready <- errChildExited
+			return
+		}
+		ready <- nil
+	}()
+
+	timer := time.NewTimer(readyTimeout)
+	defer timer.Stop()
+
+	select {
+	case err = <-ready:
+	case <-timer.C:
+		err = errChildReadyTimeout
+	}
+
+	if err != nil {
+		_ = proc.Kill()
+		go func() {
+			_, _ = proc.Wait()
+		}()
+		return err
+	}
+
+	for _, ln := range lns {
+		if ul, ok := ln.(*net.UnixListener); ok {
+			// the socket file now belongs to the child
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	return proc.Release()
+}
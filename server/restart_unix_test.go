@@ -0,0 +1,149 @@
+//go:build !windows
+
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestMain serves as the child process of TestHandoff, which re-executes the
+// test binary.
+func TestMain(m *testing.M) {
+	if isRestartChild() {
+		os.Exit(runRestartChild())
+	}
+	os.Exit(m.Run())
+}
+
+func runRestartChild() int {
+	time.AfterFunc(10*time.Second, func() {
+		os.Exit(2)
+	})
+
+	server := NewServer(gin.TestMode)
+	server.Engine().GET("/", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "child")
+		go func() {
+			_ = server.Stop(context.Background())
+		}()
+	})
+	if err := server.Start("127.0.0.1:0"); !errors.Is(err, http.ErrServerClosed) {
+		return 1
+	}
+	return 0
+}
+
+func get(t *testing.T, addr string) string {
+	t.Helper()
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get("http://" + addr + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestHandoff(t *testing.T) {
+	server := NewServer(gin.TestMode)
+	if err := server.handoff(time.Second); !errors.Is(err, errServerNotServing) {
+		t.Fatalf("expected errServerNotServing, got %v", err)
+	}
+
+	ready := make(chan struct{})
+	if _, err := server.WithOption(OnReady{Hook: func(ctx context.Context) error {
+		close(ready)
+		return nil
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	server.Engine().GET("/", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "parent")
+	})
+
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- server.Start("127.0.0.1:0")
+	}()
+	select {
+	case <-ready:
+	case err := <-startErr:
+		t.Fatal(err)
+	}
+	addr := server.openListeners[0].Addr().String()
+	if body := get(t, addr); body != "parent" {
+		t.Fatalf("parent answered %q", body)
+	}
+
+	if err := server.handoff(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	<-startErr
+
+	// the socket outlives the parent
+	if body := get(t, addr); body != "child" {
+		t.Errorf("child answered %q", body)
+	}
+}
+
+func TestOpenInheritedListeners(t *testing.T) {
+	configured, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	extra, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// FDListener owns its descriptor, hand it duplicates of the files
+	files, err := listenerFiles([]net.Listener{configured, extra, admin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fds := make([]uintptr, len(files))
+	for i, f := range files {
+		fd, err := syscall.Dup(int(f.Fd()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fds[i] = uintptr(fd)
+	}
+	closeFiles(files)
+	closeListeners([]net.Listener{configured, extra, admin})
+
+	addr := configured.Addr().String()
+	inherited := map[string]Listener{
+		"tcp:" + addr:                           FDListener{FD: fds[0]},
+		"tcp:" + extra.Addr().String():          FDListener{FD: fds[1]},
+		adminListenerPrefix + "tcp:127.0.0.1:0": FDListener{FD: fds[2]},
+	}
+	// the configured address is in use by the inherited socket, opening it
+	// anew would fail
+	lns, err := openListeners([]Listener{TCPListener{Addr: addr}}, inherited, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeListeners(lns)
+
+	if len(lns) != 2 || lns[0].Addr().String() != addr || lns[1].Addr().String() != extra.Addr().String() {
+		t.Errorf("opened %v", lns)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package server
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+var errRestartNotSupported = errors.New("graceful restart is not supported on windows")
+
+var defaultRestartSignals []os.Signal
+
+func (server *Server) handoff(time.Duration) error {
+	return errRestartNotSupported
+}
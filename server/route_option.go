@@ -0,0 +1,44 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anyufly/gin_common/routers"
+	"github.com/gin-gonic/gin"
+)
+
+var errNilAuthorizer = errors.New("authorizer can not be nil")
+
+// Authorizer checks the scopes of routers.RouteOptions.
+type Authorizer routers.Authorizer
+
+func (opt Authorizer) Apply(server *Server) error {
+	if opt == nil {
+		return errNilAuthorizer
+	}
+	server.routeOptions.SetAuthorizer(routers.Authorizer(opt))
+	return nil
+}
+
+// RateLimitClasses registers a token bucket per class, selected by routes with
+// routers.RouteOptions.RateLimitClass.
+type RateLimitClasses map[string]RateLimit
+
+func (opt RateLimitClasses) Apply(server *Server) error {
+	for class, limit := range opt {
+		if limit.Rate <= 0 || limit.Burst < 0 {
+			return fmt.Errorf("rate limit class %q: rate must be positive and burst not negative", class)
+		}
+		server.routeOptions.RegisterRateLimitClass(class, classLimiter{newRateLimiter(limit.Rate, limit.Burst)})
+	}
+	return nil
+}
+
+type classLimiter struct {
+	*rateLimiter
+}
+
+func (l classLimiter) Allow(*gin.Context) bool {
+	return l.allow()
+}
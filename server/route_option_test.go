@@ -0,0 +1,65 @@
+package server_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/anyufly/gin_common/controllers"
+	"github.com/anyufly/gin_common/response"
+	"github.com/anyufly/gin_common/routers"
+	"github.com/anyufly/gin_common/server"
+	"github.com/anyufly/gin_common/servertest"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRouteOptions(t *testing.T) {
+	ok := func(ctx *gin.Context) interface{} {
+		return response.SuccessWithData("ok")
+	}
+	slow := func(ctx *gin.Context) interface{} {
+		<-ctx.Request.Context().Done()
+		return ctx.Request.Context().Err()
+	}
+	authorizer := func(ctx *gin.Context, scopes []string) error {
+		if ctx.GetHeader("Authorization") != "admin" {
+			return errors.New("missing scope")
+		}
+		return nil
+	}
+
+	s := servertest.New(t,
+		server.Authorizer(authorizer),
+		server.RateLimitClasses{"tight": {Rate: 0.001, Burst: 1}},
+		server.Routers{Routers: []routers.Router{servertest.Router{Routes: map[string][]routers.RouteDesc{
+			"/scoped": {{Method: http.MethodGet, Controller: []controllers.ControllerFunc{ok},
+				Options: &routers.RouteOptions{Scopes: []string{"admin"}}}},
+			"/slow": {{Method: http.MethodGet, Controller: []controllers.ControllerFunc{slow},
+				Options: &routers.RouteOptions{Timeout: 10 * time.Millisecond}}},
+			"/upload": {{Method: http.MethodPost, Controller: []controllers.ControllerFunc{ok},
+				Options: &routers.RouteOptions{MaxBodyBytes: 4}}},
+			"/cached": {{Method: http.MethodGet, Controller: []controllers.ControllerFunc{ok},
+				Options: &routers.RouteOptions{CacheControl: "max-age=60", RateLimitClass: "tight"}}},
+		}}}},
+	)
+
+	s.Get("/scoped").ExpectStatus(http.StatusForbidden)
+	s.Get("/scoped").WithHeader("Authorization", "admin").ExpectStatus(http.StatusOK)
+	s.Get("/slow").ExpectStatus(http.StatusGatewayTimeout)
+	s.Post("/upload").WithBody("text/plain", []byte("12345")).ExpectStatus(http.StatusRequestEntityTooLarge)
+	s.Post("/upload").WithBody("text/plain", []byte("1234")).ExpectStatus(http.StatusOK)
+	s.Get("/cached").ExpectStatus(http.StatusOK).ExpectHeader("Cache-Control", "max-age=60")
+	s.Get("/cached").ExpectStatus(http.StatusTooManyRequests)
+
+	// the authorizer belongs to the server it was given to
+	other := servertest.New(t,
+		server.Authorizer(func(*gin.Context, []string) error { return nil }),
+		server.Routers{Routers: []routers.Router{servertest.Router{Routes: map[string][]routers.RouteDesc{
+			"/scoped": {{Method: http.MethodGet, Controller: []controllers.ControllerFunc{ok},
+				Options: &routers.RouteOptions{Scopes: []string{"admin"}}}},
+		}}}},
+	)
+	other.Get("/scoped").ExpectStatus(http.StatusOK)
+	s.Get("/scoped").ExpectStatus(http.StatusForbidden)
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+
+	"github.com/anyufly/gin_common/response"
+	"github.com/anyufly/gin_common/routers"
+	"github.com/gin-gonic/gin"
+)
+
+// RouteTable returns the route table of the applied Routers and Versioning,
+// then the other routes of the engine.
+func (server *Server) RouteTable() []routers.RouteEntry {
+	table := routers.TableOf(server.routeOptions.Routes())
+
+	known := make(map[string]bool, len(table))
+	for _, entry := range table {
+		known[entry.Method+" "+entry.Path] = true
+	}
+
+	var others []routers.RouteEntry
+	for _, route := range server.engine.Routes() {
+		if known[route.Method+" "+route.Path] {
+			continue
+		}
+		others = append(others, routers.RouteEntry{
+			Method:           route.Method,
+			Path:             route.Path,
+			GroupMiddlewares: []string{},
+			Middlewares:      []string{},
+			Controllers:      []string{route.Handler},
+		})
+	}
+	sort.SliceStable(others, func(i, j int) bool {
+		if others[i].Path != others[j].Path {
+			return others[i].Path < others[j].Path
+		}
+		return others[i].Method < others[j].Method
+	})
+	return append(table, others...)
+}
+
+// URLFor builds the path of a route named by the applied options, see
+// routers.URLFor.
+func (server *Server) URLFor(name string, params ...string) (string, error) {
+	return server.routeOptions.URLFor(name, params...)
+}
+
+// routesHandler answers the route table as JSON, or as text for ?format=table.
+func (server *Server) routesHandler(ctx *gin.Context) {
+	table := server.RouteTable()
+
+	if ctx.Query("format") == "table" || ctx.NegotiateFormat(gin.MIMEJSON, gin.MIMEPlain) == gin.MIMEPlain {
+		var buf bytes.Buffer
+		if err := routers.WriteRouteTable(&buf, table); err != nil {
+			response.UnknownError.WithErr(err).Render(ctx)
+			return
+		}
+		ctx.Data(http.StatusOK, "text/plain; charset=utf-8", buf.Bytes())
+		return
+	}
+	response.SuccessWithData(table).Render(ctx)
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/anyufly/gin_common/loggers"
+	"github.com/anyufly/gin_common/response"
+	"github.com/gin-gonic/gin"
+)
+
+var errNilAccessLogger = errors.New("access log option requires a logger")
+
+// AccessLog installs loggers.LoggerWithConfig, a reload keeps the logger when
+// Logger is nil and only swaps the skipped paths.
+type AccessLog struct {
+	Logger    loggers.GinLogger
+	SkipPaths []string
+}
+
+func (opt AccessLog) Apply(server *Server) error {
+	if opt.Logger == nil {
+		return errNilAccessLogger
+	}
+	first, err := server.applyReloadable(opt)
+	if err != nil {
+		return err
+	}
+	if first {
+		server.engine.Use(server.accessLogHandler)
+	}
+	return nil
+}
+
+func (opt AccessLog) reload(config *runtimeConfig) error {
+	if opt.Logger != nil {
+		config.accessLogger = opt.Logger
+	}
+	if config.accessLogger == nil {
+		return errNilAccessLogger
+	}
+	config.accessLog = loggers.LoggerWithConfig(loggers.LoggerConfig{
+		Logger:    config.accessLogger,
+		SkipPaths: opt.SkipPaths,
+	})
+	return nil
+}
+
+func (server *Server) accessLogHandler(ctx *gin.Context) {
+	config := requestRuntimeConfig(ctx)
+	if config == nil {
+		config = server.runtimeConfig()
+	}
+	if config.accessLog == nil {
+		ctx.Next()
+		return
+	}
+	config.accessLog(ctx)
+}
+
+// RateLimit is a server wide token bucket, Rate is in requests per second.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+func (opt RateLimit) Apply(server *Server) error {
+	first, err := server.applyReloadable(opt)
+	if err != nil {
+		return err
+	}
+	if first {
+		server.engine.Use(server.rateLimitHandler)
+	}
+	return nil
+}
+
+func (opt RateLimit) reload(config *runtimeConfig) error {
+	if opt.Rate < 0 || opt.Burst < 0 {
+		return errors.New("rate limit must not be negative")
+	}
+	if opt.Rate == 0 {
+		config.limiter = nil
+		return nil
+	}
+	if config.limiter != nil && config.limiter.rate == opt.Rate && config.limiter.burst == float64(opt.Burst) {
+		return nil
+	}
+	config.limiter = newRateLimiter(opt.Rate, opt.Burst)
+	return nil
+}
+
+func (server *Server) rateLimitHandler(ctx *gin.Context) {
+	config := requestRuntimeConfig(ctx)
+	if config == nil {
+		config = server.runtimeConfig()
+	}
+	if config.limiter != nil && !config.limiter.allow() {
+		response.TooManyRequestsError.Render(ctx)
+		ctx.Abort()
+		return
+	}
+	ctx.Next()
+}
+
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	b := float64(burst)
+	if b < 1 {
+		b = math.Max(1, rate)
+	}
+	return &rateLimiter{rate: rate, burst: b, tokens: b, last: time.Now()}
+}
+
+func (l *rateLimiter) allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Maintenance answers every request outside AllowPaths with 503 while enabled.
+type Maintenance struct {
+	Enabled    bool
+	Message    string
+	AllowPaths []string
+}
+
+func (opt Maintenance) Apply(server *Server) error {
+	first, err := server.applyReloadable(opt)
+	if err != nil {
+		return err
+	}
+	if first {
+		server.engine.Use(server.maintenanceHandler)
+	}
+	return nil
+}
+
+func (opt Maintenance) reload(config *runtimeConfig) error {
+	config.maintenance = &opt
+	return nil
+}
+
+func (server *Server) maintenanceHandler(ctx *gin.Context) {
+	config := requestRuntimeConfig(ctx)
+	if config == nil {
+		config = server.runtimeConfig()
+	}
+	m := config.maintenance
+	if m == nil || !m.Enabled {
+		ctx.Next()
+		return
+	}
+	for _, path := range m.AllowPaths {
+		if ctx.Request.URL.Path == path {
+			ctx.Next()
+			return
+		}
+	}
+
+	er := response.ServiceUnavailableError
+	if m.Message != "" {
+		er = er.WithMsg(m.Message)
+	}
+	er.Render(ctx)
+	ctx.Abort()
+}
@@ -2,87 +2,282 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/anyufly/gin_common/common"
+	"github.com/anyufly/gin_common/routers"
 	"github.com/gin-gonic/gin"
 )
 
 var errServerEngineNotInit = errors.New("server engine was not initialized, please call server.NewServer() to  initialize server")
+var errNilHook = errors.New("lifecycle hook can not be nil")
 
 type Server struct {
 	engine *gin.Engine
 	srv    *http.Server
+	mu     sync.Mutex
+
+	stopping bool
+	stopped  chan struct{}
+	health   *Health
+
+	tlsConfig *tls.Config
+	listeners []Listener
+
+	httpConfig *HTTPServer
+	http2      *HTTP2
+	h2cConns   *h2cConns
+
+	handlerWrappers []func(next http.Handler) http.Handler
+
+	runtime    atomic.Pointer[runtimeConfig]
+	runtimeMu  sync.Mutex
+	reloadable map[reflect.Type]bool
+	configPath string
+
+	applied       map[reflect.Type]int
+	routesApplied bool
+	routeOptions  *routers.OptionRegistry
+	statics       []*staticHandler
+	apiDoc        *APIDoc
+
+	admin    *Admin
+	adminSrv *http.Server
+
+	restart           *GracefulRestart
+	openListeners     []net.Listener
+	openListenerNames []string
+
+	shutdownTimeout time.Duration
+	shutdownSignals []os.Signal
+	startHooks      []lifecycleHook
+	readyHooks      []lifecycleHook
+	shutdownHooks   []lifecycleHook
 }
 
 func NewServer(mode string) *Server {
 	gin.SetMode(mode)
-	return &Server{
-		engine: gin.New(),
+	engine := gin.New()
+	// trust no proxy until the TrustedProxies option says otherwise
+	_ = engine.SetTrustedProxies(nil)
+	server := &Server{
+		engine:       engine,
+		reloadable:   make(map[reflect.Type]bool),
+		applied:      make(map[reflect.Type]int),
+		stopped:      make(chan struct{}),
+		routeOptions: routers.NewOptionRegistry(),
 	}
+	server.runtime.Store(&runtimeConfig{})
+	server.wrapHandler(server.runtimeHandler)
+	server.wrapHandler(server.stoppingHandler)
+	return server
+}
+
+// stoppingHandler exposes the stop signal to the handlers, see
+// common.ServerStopping.
+func (server *Server) stoppingHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := common.WithServerStopping(r.Context(), server.stopped)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
 }
 
 func (server *Server) Engine() *gin.Engine {
 	return server.engine
 }
 
-func resolveAddress(addr []string) string {
-	switch len(addr) {
-	case 0:
+func (server *Server) wrapHandler(wrapper func(next http.Handler) http.Handler) {
+	server.handlerWrappers = append(server.handlerWrappers, wrapper)
+}
+
+func (server *Server) Handler() http.Handler {
+	var handler http.Handler = server.engine
+	for i := len(server.handlerWrappers) - 1; i >= 0; i-- {
+		handler = server.handlerWrappers[i](handler)
+	}
+	return handler
+}
+
+func resolveListeners(configured []Listener, addr []string) []Listener {
+	listeners := make([]Listener, 0, len(configured)+len(addr))
+	listeners = append(listeners, configured...)
+	for _, a := range addr {
+		listeners = append(listeners, parseListener(a))
+	}
+
+	if len(listeners) == 0 {
 		if port := os.Getenv("PORT"); port != "" {
-			return ":" + port
+			return []Listener{TCPListener{Addr: ":" + port}}
 		}
-		return ":8080"
-	case 1:
-		return addr[0]
-	default:
-		panic("too many parameters")
+		return []Listener{TCPListener{Addr: ":8080"}}
 	}
+	return listeners
 }
 
-func (server *Server) Start(addr ...string) error {
+func (server *Server) Start(addr ...string) (err error) {
 
 	if server.engine == nil {
 		return errServerEngineNotInit
 	}
 
-	err := server.engine.SetTrustedProxies(nil)
+	server.mu.Lock()
+	startHooks, readyHooks := server.startHooks, server.readyHooks
+	configured := server.listeners
+	server.mu.Unlock()
+
+	// a failed start releases what the start hooks acquired without waiting
+	// for Stop
+	defer func() {
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			err = combineErrors(err, server.runShutdownHooks())
+		}
+	}()
+
+	if err := server.runHooks(context.Background(), startHooks); err != nil {
+		return fmt.Errorf("start hook: %w", err)
+	}
+
+	listeners := resolveListeners(configured, addr)
+	inherited := inheritedListeners()
+
+	adminLns, adminNames, err := server.openAdminListeners(inherited)
+	if err != nil {
+		return err
+	}
+	lns, err := openListeners(listeners, inherited, "")
+	if err != nil {
+		closeListeners(adminLns)
+		return err
+	}
+
+	srv, config := server.newHTTPServer(lns[0].Addr().String())
+	cleartextConns, err := server.configureHTTP2(srv)
 	if err != nil {
+		closeListeners(lns)
+		closeListeners(adminLns)
 		return err
 	}
+	adminSrv := server.newAdminServer()
+	server.mu.Lock()
+	if server.stopping {
+		server.mu.Unlock()
+		closeListeners(lns)
+		closeListeners(adminLns)
+		return http.ErrServerClosed
+	}
+	server.srv = srv
+	server.h2cConns = cleartextConns
+	server.adminSrv = adminSrv
+	server.openListeners = append(lns, adminLns...)
+	server.openListenerNames = append(listenerNames(listeners, lns), adminNames...)
+	server.mu.Unlock()
 
-	address := resolveAddress(addr)
+	closeAll := func() {
+		_ = srv.Close()
+		if adminSrv != nil {
+			_ = adminSrv.Close()
+		}
+	}
 
-	server.srv = &http.Server{
-		Addr:    address,
-		Handler: server.engine,
+	useTLS := srv.TLSConfig != nil
+	total := len(lns) + len(adminLns)
+	serveErr := make(chan error, total)
+	for _, ln := range limitListeners(lns, config.MaxConns) {
+		go func(ln net.Listener) {
+			serveErr <- serve(srv, ln, useTLS)
+		}(ln)
+	}
+	for _, ln := range adminLns {
+		go func(ln net.Listener) {
+			serveErr <- adminSrv.Serve(ln)
+		}(ln)
 	}
 
-	err = server.srv.ListenAndServe()
+	if err = server.runHooks(context.Background(), readyHooks); err != nil {
+		closeAll()
+		waitServe(serveErr, total)
+		return fmt.Errorf("ready hook: %w", err)
+	}
 
-	if err != nil {
+	if err = notifyParentReady(); err != nil {
+		closeAll()
+		waitServe(serveErr, total)
 		return err
 	}
-	return nil
+
+	err = <-serveErr
+	if !errors.Is(err, http.ErrServerClosed) {
+		// one listener failed, take the others down with it
+		closeAll()
+	}
+	waitServe(serveErr, total-1)
+	return err
+}
+
+func serve(srv *http.Server, ln net.Listener, useTLS bool) error {
+	if useTLS {
+		return srv.ServeTLS(ln, "", "")
+	}
+	return srv.Serve(ln)
+}
+
+func waitServe(serveErr <-chan error, n int) {
+	for i := 0; i < n; i++ {
+		<-serveErr
+	}
 }
 
 func (server *Server) Stop(ctx context.Context) error {
-	if server.srv == nil {
-		return nil
+	server.mu.Lock()
+	if !server.stopping {
+		close(server.stopped)
 	}
-	return server.srv.Shutdown(ctx)
+	server.stopping = true
+	srv, cleartextConns, adminSrv := server.srv, server.h2cConns, server.adminSrv
+	server.mu.Unlock()
+
+	var err, adminErr error
+	if srv != nil {
+		err = srv.Shutdown(ctx)
+	}
+	if cleartextConns != nil {
+		if h2cErr := cleartextConns.shutdown(ctx); err == nil {
+			err = h2cErr
+		}
+	}
+	if adminSrv != nil {
+		adminErr = adminSrv.Shutdown(ctx)
+	}
+	return combineErrors(err, adminErr, server.runShutdownHooks())
 }
 
 func (server *Server) WithOption(opts ...Option) (*Server, error) {
 	if server.engine == nil {
 		return nil, errServerEngineNotInit
 	}
-	for _, opt := range opts {
-		err := opt.Apply(server)
+	sorted, err := server.sortOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, opt := range sorted {
+		err = opt.Apply(server)
 		if err != nil {
 			return nil, err
 		}
+		server.applied[optionType(opt)]++
+		if optionPhase(opt) >= PhaseRoutes {
+			server.routesApplied = true
+		}
 	}
 
 	return server, nil
@@ -0,0 +1,271 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultStaticIndex = "index.html"
+	immutableCache     = "public, max-age=31536000, immutable"
+	revalidateCache    = "no-cache"
+)
+
+var (
+	errStaticSource = errors.New("static needs exactly one of Dir and FS")
+	// hashedAsset matches app.3f2a9c1b.js or app-3f2a9c1b.css, the hash holds
+	// a digit so that my-component.js is not taken for one.
+	hashedAsset = regexp.MustCompile(`[.-]([0-9a-fA-F]{8,})\.[0-9a-zA-Z]+$`)
+)
+
+// Static serves files from Dir or FS under Prefix for the requests no route
+// matched. Hashed files are cached as immutable, the others revalidated with
+// their ETag, and name.br or name.gz variants are preferred.
+type Static struct {
+	Prefix string
+	Dir    string
+	FS     fs.FS
+	// Index is served for directories, index.html by default.
+	Index string
+	// Browse lists directories without an Index.
+	Browse bool
+	// SPA serves the root Index for unknown paths without an extension
+	// outside the API.
+	SPA bool
+	// MaxAge of the files not Hashed, zero revalidates them.
+	MaxAge time.Duration
+	// Hashed matches the base names carrying a content hash.
+	Hashed *regexp.Regexp
+}
+
+type staticHandler struct {
+	Static
+	fsys fs.FS
+
+	apiOnce     sync.Once
+	apiPrefixes map[string]bool
+	server      *Server
+
+	etags sync.Map
+}
+
+func (opt Static) Apply(server *Server) error {
+	if (opt.Dir == "") == (opt.FS == nil) {
+		return errStaticSource
+	}
+	h := &staticHandler{Static: opt, fsys: opt.FS, server: server}
+	if opt.Dir != "" {
+		info, err := os.Stat(opt.Dir)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("static dir %s is not a directory", opt.Dir)
+		}
+		h.fsys = os.DirFS(opt.Dir)
+	}
+	h.Prefix = path.Join("/", opt.Prefix)
+	if h.Index == "" {
+		h.Index = defaultStaticIndex
+	}
+
+	if len(server.statics) == 0 {
+		server.engine.NoRoute(server.serveStatic)
+	}
+	server.statics = append(server.statics, h)
+	return nil
+}
+
+func (server *Server) serveStatic(ctx *gin.Context) {
+	if ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead {
+		return
+	}
+	for _, h := range server.statics {
+		if h.serve(ctx) {
+			ctx.Abort()
+			return
+		}
+	}
+}
+
+// relative returns the fs path of the request path, false outside Prefix.
+func (h *staticHandler) relative(p string) (string, bool) {
+	p = path.Clean("/" + p)
+	if h.Prefix != "/" {
+		if p != h.Prefix && !strings.HasPrefix(p, h.Prefix+"/") {
+			return "", false
+		}
+		p = strings.TrimPrefix(p, h.Prefix)
+	}
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		p = "."
+	}
+	return p, true
+}
+
+func (h *staticHandler) serve(ctx *gin.Context) bool {
+	name, ok := h.relative(ctx.Request.URL.Path)
+	if !ok {
+		return false
+	}
+
+	info, err := fs.Stat(h.fsys, name)
+	if err == nil && info.IsDir() {
+		index := path.Join(name, h.Index)
+		if indexInfo, err := fs.Stat(h.fsys, index); err == nil && !indexInfo.IsDir() {
+			return h.serveFile(ctx, index, indexInfo)
+		}
+		if h.Browse {
+			http.StripPrefix(h.Prefix, http.FileServer(http.FS(h.fsys))).ServeHTTP(ctx.Writer, ctx.Request)
+			return true
+		}
+	} else if err == nil {
+		return h.serveFile(ctx, name, info)
+	}
+
+	if h.SPA && path.Ext(name) == "" && !h.isAPI(ctx.Request.URL.Path) {
+		if indexInfo, err := fs.Stat(h.fsys, h.Index); err == nil && !indexInfo.IsDir() {
+			return h.serveFile(ctx, h.Index, indexInfo)
+		}
+	}
+	return false
+}
+
+// isAPI reports whether p is under the first segment of a registered route.
+func (h *staticHandler) isAPI(p string) bool {
+	h.apiOnce.Do(func() {
+		h.apiPrefixes = make(map[string]bool)
+		for _, route := range h.server.engine.Routes() {
+			first, _, _ := strings.Cut(strings.TrimPrefix(route.Path, "/"), "/")
+			if first != "" && first[0] != ':' && first[0] != '*' {
+				h.apiPrefixes[first] = true
+			}
+		}
+	})
+	first, _, _ := strings.Cut(strings.TrimPrefix(p, "/"), "/")
+	return h.apiPrefixes[first]
+}
+
+func (h *staticHandler) serveFile(ctx *gin.Context, name string, info fs.FileInfo) bool {
+	served, servedInfo, encoding := h.precompressed(ctx, name)
+	if served == "" {
+		served, servedInfo = name, info
+	}
+
+	f, err := h.fsys.Open(served)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return false
+		}
+		content = bytes.NewReader(b)
+	}
+
+	etag, err := h.etag(served, servedInfo, content)
+	if err != nil {
+		return false
+	}
+
+	header := ctx.Writer.Header()
+	header.Set("ETag", etag)
+	header.Add("Vary", "Accept-Encoding")
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+	switch {
+	case h.isHashed(path.Base(name)):
+		header.Set("Cache-Control", immutableCache)
+	case h.MaxAge > 0:
+		header.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.MaxAge.Seconds())))
+	default:
+		header.Set("Cache-Control", revalidateCache)
+	}
+
+	// the content type is guessed from the original name, not the .br/.gz one
+	http.ServeContent(ctx.Writer, ctx.Request, path.Base(name), servedInfo.ModTime(), content)
+	return true
+}
+
+func (h *staticHandler) isHashed(name string) bool {
+	if h.Hashed != nil {
+		return h.Hashed.MatchString(name)
+	}
+	match := hashedAsset.FindStringSubmatch(name)
+	return match != nil && strings.ContainsAny(match[1], "0123456789")
+}
+
+// precompressed returns the name.br or name.gz variant the client accepts.
+func (h *staticHandler) precompressed(ctx *gin.Context, name string) (string, fs.FileInfo, string) {
+	if ctx.Request.Header.Get("Range") != "" {
+		return "", nil, ""
+	}
+	accept := ctx.Request.Header.Get("Accept-Encoding")
+	for _, variant := range [...]struct{ encoding, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+		if !acceptsEncoding(accept, variant.encoding) {
+			continue
+		}
+		if info, err := fs.Stat(h.fsys, name+variant.ext); err == nil && !info.IsDir() {
+			return name + variant.ext, info, variant.encoding
+		}
+	}
+	return "", nil, ""
+}
+
+func acceptsEncoding(accept string, encoding string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), encoding) {
+			continue
+		}
+		params = strings.TrimSpace(params)
+		if !strings.HasPrefix(params, "q=") {
+			return true
+		}
+		weight, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64)
+		return err == nil && weight > 0
+	}
+	return false
+}
+
+// etag derives the ETag from size and modification time, or the content.
+func (h *staticHandler) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	if !info.ModTime().IsZero() {
+		return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()), nil
+	}
+	if etag, ok := h.etags.Load(name); ok {
+		return etag.(string), nil
+	}
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(sum.Sum(nil)[:16]) + `"`
+	h.etags.Store(name, etag)
+	return etag, nil
+}
@@ -0,0 +1,39 @@
+package server_test
+
+import (
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/anyufly/gin_common/server"
+	"github.com/anyufly/gin_common/servertest"
+)
+
+func TestStaticCacheControl(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":           {Data: []byte("<html></html>")},
+		"app.3f2a9c1b.js":      {Data: []byte("hashed")},
+		"jquery-accordion.js":  {Data: []byte("plain")},
+		"app.settings.json":    {Data: []byte("{}")},
+		"vendor-deadbeef.css":  {Data: []byte("no digit")},
+		"chunk-0123abcdef9.js": {Data: []byte("hashed")},
+	}
+	s := servertest.New(t, server.Static{FS: fsys, SPA: true})
+
+	tests := []struct {
+		path  string
+		cache string
+	}{
+		{"/app.3f2a9c1b.js", "public, max-age=31536000, immutable"},
+		{"/chunk-0123abcdef9.js", "public, max-age=31536000, immutable"},
+		{"/jquery-accordion.js", "no-cache"},
+		{"/app.settings.json", "no-cache"},
+		{"/vendor-deadbeef.css", "no-cache"},
+		{"/", "no-cache"},
+		{"/some/page", "no-cache"},
+	}
+	for _, tt := range tests {
+		s.Get(tt.path).ExpectStatus(http.StatusOK).ExpectHeader("Cache-Control", tt.cache)
+	}
+	s.Get("/missing.js").ExpectStatus(http.StatusNotFound)
+}
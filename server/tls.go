@@ -0,0 +1,137 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultCertReloadInterval = time.Minute
+
+var (
+	errTLSCertificateMissing = errors.New("tls option requires CertFile and KeyFile or a Config with certificates")
+	errInvalidClientCA       = errors.New("no valid certificate found in client ca file")
+)
+
+type TLS struct {
+	CertFile       string
+	KeyFile        string
+	Config         *tls.Config
+	ClientCAFile   string
+	ClientAuth     tls.ClientAuthType
+	ReloadInterval time.Duration
+}
+
+func (opt TLS) Apply(server *Server) error {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opt.Config != nil {
+		config = opt.Config.Clone()
+	}
+
+	switch {
+	case opt.CertFile != "" && opt.KeyFile != "":
+		interval := opt.ReloadInterval
+		if interval <= 0 {
+			interval = defaultCertReloadInterval
+		}
+		reloader, err := newCertReloader(opt.CertFile, opt.KeyFile, interval)
+		if err != nil {
+			return err
+		}
+		config.GetCertificate = reloader.getCertificate
+	case len(config.Certificates) == 0 && config.GetCertificate == nil && config.GetConfigForClient == nil:
+		return errTLSCertificateMissing
+	}
+
+	if opt.ClientCAFile != "" {
+		pem, err := os.ReadFile(opt.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errInvalidClientCA
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	if opt.ClientAuth != tls.NoClientCert {
+		config.ClientAuth = opt.ClientAuth
+	}
+
+	server.tlsConfig = config
+	return nil
+}
+
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func newCertReloader(certFile, keyFile string, interval time.Duration) (*certReloader, error) {
+	cr := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+	}
+	modTime, err := cr.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	if err = cr.load(modTime); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+func (cr *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{cr.certFile, cr.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (cr *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return err
+	}
+	cr.cert = &cert
+	cr.modTime = modTime
+	cr.checkedAt = time.Now()
+	return nil
+}
+
+func (cr *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	if time.Since(cr.checkedAt) < cr.interval {
+		return cr.cert, nil
+	}
+	cr.checkedAt = time.Now()
+
+	modTime, err := cr.latestModTime()
+	if err != nil || !modTime.After(cr.modTime) {
+		// keep serving the last good certificate while files are missing or being rewritten
+		return cr.cert, nil
+	}
+	_ = cr.load(modTime)
+	return cr.cert, nil
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeCert writes a self-signed certificate for commonName and its key,
+// modified at modTime.
+func writeCert(t *testing.T, certFile, keyFile, commonName string, modTime time.Time) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: der},
+		keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+	}
+	for file, block := range files {
+		if err = os.WriteFile(file, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func certName(t *testing.T, cert *tls.Certificate) string {
+	t.Helper()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	modTime := time.Now().Add(-time.Hour)
+	writeCert(t, certFile, keyFile, "first", modTime)
+
+	cr, err := newCertReloader(certFile, keyFile, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func() string {
+		cert, err := cr.getCertificate(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return certName(t, cert)
+	}
+	if name := get(); name != "first" {
+		t.Fatalf("serving %q, want first", name)
+	}
+
+	// files are checked once per interval
+	writeCert(t, certFile, keyFile, "second", modTime.Add(time.Minute))
+	if name := get(); name != "first" {
+		t.Errorf("serving %q before the interval elapsed, want first", name)
+	}
+	cr.interval = 0
+	if name := get(); name != "second" {
+		t.Errorf("serving %q after a change, want second", name)
+	}
+
+	// a half-written pair keeps the last good certificate
+	if err = os.WriteFile(keyFile, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chtimes(keyFile, modTime.Add(2*time.Minute), modTime.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if name := get(); name != "second" {
+		t.Errorf("serving %q with an invalid key, want second", name)
+	}
+	if err = os.Remove(certFile); err != nil {
+		t.Fatal(err)
+	}
+	if name := get(); name != "second" {
+		t.Errorf("serving %q with a missing file, want second", name)
+	}
+
+	writeCert(t, certFile, keyFile, "third", modTime.Add(3*time.Minute))
+	if name := get(); name != "third" {
+		t.Errorf("serving %q after a fix, want third", name)
+	}
+}
+
+func TestTLSApply(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCert(t, certFile, keyFile, "server", time.Now())
+	invalidCA := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(invalidCA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		opt        TLS
+		err        error
+		clientAuth tls.ClientAuthType
+	}{
+		{name: "files", opt: TLS{CertFile: certFile, KeyFile: keyFile}},
+		{name: "missing certificate", opt: TLS{}, err: errTLSCertificateMissing},
+		{name: "invalid client ca", opt: TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: invalidCA}, err: errInvalidClientCA},
+		{
+			name:       "client ca requires client certificates",
+			opt:        TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile},
+			clientAuth: tls.RequireAndVerifyClientCert,
+		},
+		{
+			name:       "explicit client auth",
+			opt:        TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile, ClientAuth: tls.VerifyClientCertIfGiven},
+			clientAuth: tls.VerifyClientCertIfGiven,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(gin.TestMode)
+			err := tt.opt.Apply(server)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if server.tlsConfig.GetCertificate == nil {
+				t.Error("certificate is not served from the reloader")
+			}
+			if server.tlsConfig.ClientAuth != tt.clientAuth {
+				t.Errorf("client auth %v, want %v", server.tlsConfig.ClientAuth, tt.clientAuth)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package server
+
+import "github.com/anyufly/gin_common/routers"
+
+// Versioning serves the versions under Base/<Name>, and the unversioned paths
+// under Base through routers.VersionResolver. Versions are listed oldest
+// first.
+type Versioning struct {
+	Base           string
+	Versions       []routers.Version
+	Header         string
+	MediaTypeParam string
+}
+
+func (opt Versioning) Apply(server *Server) error {
+	resolver, err := server.routeOptions.CombineVersions(server.engine, routers.Versioning(opt))
+	if err != nil {
+		return err
+	}
+	server.wrapHandler(resolver.Handler)
+	return nil
+}
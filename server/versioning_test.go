@@ -0,0 +1,66 @@
+package server_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/anyufly/gin_common/controllers"
+	"github.com/anyufly/gin_common/response"
+	"github.com/anyufly/gin_common/routers"
+	"github.com/anyufly/gin_common/server"
+	"github.com/anyufly/gin_common/servertest"
+	"github.com/gin-gonic/gin"
+)
+
+func TestVersionDeprecation(t *testing.T) {
+	ok := func(ctx *gin.Context) interface{} { return response.SuccessWithData("ok") }
+	get := func(controller controllers.ControllerFunc) routers.RouteDesc {
+		return routers.RouteDesc{Method: http.MethodGet, Controller: []controllers.ControllerFunc{controller}}
+	}
+	own := get(ok)
+	own.Deprecation = &routers.Deprecation{Link: "https://example.com/own"}
+
+	s := servertest.New(t, server.Versioning{
+		Base: "/api",
+		Versions: []routers.Version{
+			{
+				Name: "v1",
+				Routers: []routers.Router{servertest.Router{Name: "users", Routes: map[string][]routers.RouteDesc{
+					"/":    {get(ok)},
+					"/own": {own},
+				}}},
+				Deprecation: &routers.Deprecation{
+					Sunset: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+					Link:   "https://example.com/v1",
+				},
+			},
+			{
+				Name: "v2",
+				Routers: []routers.Router{servertest.Router{Name: "users", Routes: map[string][]routers.RouteDesc{
+					"/": {get(ok)},
+				}}},
+			},
+		},
+	})
+
+	resp := s.Get("/api/v1/users/").ExpectStatus(http.StatusOK).
+		ExpectHeader("Deprecation", "true").
+		ExpectHeader("Sunset", "Tue, 01 Jan 2030 00:00:00 GMT")
+	if links := resp.Recorder.Header().Values("Link"); len(links) != 1 {
+		t.Errorf("expected one Link header, got %q", links)
+	}
+
+	resp = s.Get("/api/v1/users/own").ExpectStatus(http.StatusOK).
+		ExpectHeader("Deprecation", "true").
+		ExpectHeader("Sunset", "").
+		ExpectHeader("Link", `<https://example.com/own>; rel="deprecation"`)
+	if links := resp.Recorder.Header().Values("Link"); len(links) != 1 {
+		t.Errorf("expected one Link header, got %q", links)
+	}
+
+	s.Get("/api/v2/users/").ExpectStatus(http.StatusOK).ExpectHeader("Deprecation", "")
+	s.Get("/api/users/").WithHeader("API-Version", "1").ExpectStatus(http.StatusOK).
+		ExpectHeader("API-Version", "v1").
+		ExpectHeader("Deprecation", "true")
+}
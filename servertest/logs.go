@@ -0,0 +1,95 @@
+package servertest
+
+import (
+	"sync"
+
+	"github.com/anyufly/gin_common/loggers"
+)
+
+type LogEntry struct {
+	Name   string
+	Level  string
+	Msg    string
+	Fields map[string]interface{}
+}
+
+// LogRecorder is a loggers.GinLogger keeping every entry in memory, pass it
+// to server.AccessLog or loggers.Logger to assert on what requests logged.
+type LogRecorder struct {
+	name    string
+	entries *logEntries
+}
+
+type logEntries struct {
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+func NewLogRecorder() *LogRecorder {
+	return &LogRecorder{entries: &logEntries{}}
+}
+
+func (rec *LogRecorder) Name(name string) loggers.GinLogger {
+	if rec.name != "" {
+		name = rec.name + "." + name
+	}
+	return &LogRecorder{name: name, entries: rec.entries}
+}
+
+func (rec *LogRecorder) Info(msg string, keyAndValues ...interface{}) {
+	rec.record("info", msg, keyAndValues)
+}
+
+func (rec *LogRecorder) Debug(msg string, keyAndValues ...interface{}) {
+	rec.record("debug", msg, keyAndValues)
+}
+
+func (rec *LogRecorder) Warn(msg string, keyAndValues ...interface{}) {
+	rec.record("warn", msg, keyAndValues)
+}
+
+func (rec *LogRecorder) Error(msg string, keyAndValues ...interface{}) {
+	rec.record("error", msg, keyAndValues)
+}
+
+func (rec *LogRecorder) record(level, msg string, keyAndValues []interface{}) {
+	fields := make(map[string]interface{}, len(keyAndValues)/2)
+	for i := 0; i+1 < len(keyAndValues); i += 2 {
+		if key, ok := keyAndValues[i].(string); ok {
+			fields[key] = keyAndValues[i+1]
+		}
+	}
+
+	rec.entries.mu.Lock()
+	defer rec.entries.mu.Unlock()
+	rec.entries.entries = append(rec.entries.entries, LogEntry{
+		Name:   rec.name,
+		Level:  level,
+		Msg:    msg,
+		Fields: fields,
+	})
+}
+
+// Entries returns every recorded entry, whatever logger name it was written with.
+func (rec *LogRecorder) Entries() []LogEntry {
+	rec.entries.mu.Lock()
+	defer rec.entries.mu.Unlock()
+	return append([]LogEntry(nil), rec.entries.entries...)
+}
+
+// Named returns the entries written with the given logger name, e.g. "request_error".
+func (rec *LogRecorder) Named(name string) []LogEntry {
+	var named []LogEntry
+	for _, entry := range rec.Entries() {
+		if entry.Name == name {
+			named = append(named, entry)
+		}
+	}
+	return named
+}
+
+func (rec *LogRecorder) Reset() {
+	rec.entries.mu.Lock()
+	defer rec.entries.mu.Unlock()
+	rec.entries.entries = nil
+}
@@ -0,0 +1,147 @@
+package servertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type Response struct {
+	t        testing.TB
+	Recorder *httptest.ResponseRecorder
+
+	decoded    interface{}
+	decodedErr error
+	isDecoded  bool
+}
+
+func (resp *Response) Status() int {
+	return resp.Recorder.Code
+}
+
+func (resp *Response) Body() string {
+	return resp.Recorder.Body.String()
+}
+
+func (resp *Response) JSON() (interface{}, error) {
+	if !resp.isDecoded {
+		resp.isDecoded = true
+		resp.decodedErr = json.Unmarshal(resp.Recorder.Body.Bytes(), &resp.decoded)
+	}
+	return resp.decoded, resp.decodedErr
+}
+
+// DecodeData decodes the data field of the response envelope into v.
+func (resp *Response) DecodeData(v interface{}) *Response {
+	resp.t.Helper()
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Recorder.Body.Bytes(), &envelope); err != nil {
+		resp.t.Errorf("servertest: decode response body: %v", err)
+		return resp
+	}
+	if err := json.Unmarshal(envelope.Data, v); err != nil {
+		resp.t.Errorf("servertest: decode response data: %v", err)
+	}
+	return resp
+}
+
+func (resp *Response) ExpectStatus(status int) *Response {
+	resp.t.Helper()
+	if resp.Recorder.Code != status {
+		resp.t.Errorf("servertest: expect status %d, got %d, body: %s", status, resp.Recorder.Code, resp.Body())
+	}
+	return resp
+}
+
+func (resp *Response) ExpectHeader(key, value string) *Response {
+	resp.t.Helper()
+	if got := resp.Recorder.Header().Get(key); got != value {
+		resp.t.Errorf("servertest: expect header %s %q, got %q", key, value, got)
+	}
+	return resp
+}
+
+// ExpectJSONPath compares the value at a dotted path, array elements are
+// addressed by index, e.g. "data.items.0.id".
+func (resp *Response) ExpectJSONPath(path string, expected interface{}) *Response {
+	resp.t.Helper()
+
+	doc, err := resp.JSON()
+	if err != nil {
+		resp.t.Errorf("servertest: decode response body: %v, body: %s", err, resp.Body())
+		return resp
+	}
+	got, err := lookupPath(doc, path)
+	if err != nil {
+		resp.t.Errorf("servertest: %v, body: %s", err, resp.Body())
+		return resp
+	}
+	want, err := normalize(expected)
+	if err != nil {
+		resp.t.Errorf("servertest: normalize expected value: %v", err)
+		return resp
+	}
+	if !reflect.DeepEqual(got, want) {
+		resp.t.Errorf("servertest: expect %s to be %v, got %v", path, want, got)
+	}
+	return resp
+}
+
+func (resp *Response) ExpectCode(code string) *Response {
+	resp.t.Helper()
+	return resp.ExpectJSONPath("code", code)
+}
+
+func (resp *Response) ExpectMsg(msg string) *Response {
+	resp.t.Helper()
+	return resp.ExpectJSONPath("msg", msg)
+}
+
+func (resp *Response) ExpectData(data interface{}) *Response {
+	resp.t.Helper()
+	return resp.ExpectJSONPath("data", data)
+}
+
+func lookupPath(doc interface{}, path string) (interface{}, error) {
+	current := doc
+	if path == "" {
+		return current, nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("json path %s: key %q not found", path, key)
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("json path %s: invalid index %q", path, key)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("json path %s: can not descend into %q", path, key)
+		}
+	}
+	return current, nil
+}
+
+// normalize gives expected values the shape encoding/json decodes into, so
+// that 1 equals 1.0 and structs equal maps.
+func normalize(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	err = json.Unmarshal(data, &normalized)
+	return normalized, err
+}
@@ -0,0 +1,27 @@
+package servertest
+
+import "github.com/anyufly/gin_common/routers"
+
+// Router is a routers.Router built from literals, for the routes a test
+// serves:
+//
+//	servertest.New(t, server.Routers{Routers: []routers.Router{servertest.Router{
+//		Routes: map[string][]routers.RouteDesc{"/users/:id": {{Method: http.MethodGet, Controller: ...}}},
+//	}}})
+type Router struct {
+	Name        string
+	Routes      map[string][]routers.RouteDesc
+	Middlewares []interface{}
+}
+
+func (r Router) GroupName() string {
+	return r.Name
+}
+
+func (r Router) GroupConfig() map[string][]routers.RouteDesc {
+	return r.Routes
+}
+
+func (r Router) GroupMiddleware() []interface{} {
+	return r.Middlewares
+}
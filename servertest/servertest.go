@@ -0,0 +1,158 @@
+// Package servertest runs servers built with the server package in process,
+// without binding a port:
+//
+//	logs := servertest.NewLogRecorder()
+//	s := servertest.New(t, server.AccessLog{Logger: logs}, server.Routers{Base: "/v1", Routers: routers})
+//	s.Get("/v1/users/1").WithHeader("Authorization", token).
+//		ExpectStatus(http.StatusOK).
+//		ExpectCode(response.SuccessCode).
+//		ExpectJSONPath("data.id", 1)
+package servertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/anyufly/gin_common/server"
+	"github.com/gin-gonic/gin"
+)
+
+type Server struct {
+	t      testing.TB
+	server *server.Server
+}
+
+func New(t testing.TB, opts ...server.Option) *Server {
+	t.Helper()
+
+	srv, err := server.NewServer(gin.TestMode).WithOption(opts...)
+	if err != nil {
+		t.Fatalf("servertest: apply options: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = srv.Stop(context.Background())
+	})
+	if err = srv.Prepare(); err != nil {
+		t.Fatalf("servertest: %v", err)
+	}
+
+	return &Server{t: t, server: srv}
+}
+
+func (s *Server) Server() *server.Server {
+	return s.server
+}
+
+func (s *Server) Request(method, path string) *Request {
+	return &Request{
+		s:          s,
+		method:     method,
+		path:       path,
+		header:     make(http.Header),
+		query:      make(url.Values),
+		remoteAddr: "192.0.2.1:1234",
+	}
+}
+
+func (s *Server) Get(path string) *Request {
+	return s.Request(http.MethodGet, path)
+}
+
+func (s *Server) Post(path string) *Request {
+	return s.Request(http.MethodPost, path)
+}
+
+func (s *Server) Put(path string) *Request {
+	return s.Request(http.MethodPut, path)
+}
+
+func (s *Server) Patch(path string) *Request {
+	return s.Request(http.MethodPatch, path)
+}
+
+func (s *Server) Delete(path string) *Request {
+	return s.Request(http.MethodDelete, path)
+}
+
+type Request struct {
+	s          *Server
+	method     string
+	path       string
+	header     http.Header
+	query      url.Values
+	body       []byte
+	remoteAddr string
+}
+
+func (r *Request) WithHeader(key, value string) *Request {
+	r.header.Add(key, value)
+	return r
+}
+
+func (r *Request) WithQuery(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+func (r *Request) WithRemoteAddr(addr string) *Request {
+	r.remoteAddr = addr
+	return r
+}
+
+func (r *Request) WithBody(contentType string, body []byte) *Request {
+	r.header.Set("Content-Type", contentType)
+	r.body = body
+	return r
+}
+
+func (r *Request) WithJSON(v interface{}) *Request {
+	r.s.t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		r.s.t.Fatalf("servertest: marshal json body: %v", err)
+	}
+	return r.WithBody("application/json", body)
+}
+
+func (r *Request) WithForm(values url.Values) *Request {
+	return r.WithBody("application/x-www-form-urlencoded", []byte(values.Encode()))
+}
+
+func (r *Request) Do() *Response {
+	r.s.t.Helper()
+
+	target := r.path
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + r.query.Encode()
+	}
+
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	req := httptest.NewRequest(r.method, target, body)
+	req.RemoteAddr = r.remoteAddr
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+
+	recorder := httptest.NewRecorder()
+	r.s.server.Handler().ServeHTTP(recorder, req)
+	return &Response{t: r.s.t, Recorder: recorder}
+}
+
+func (r *Request) ExpectStatus(status int) *Response {
+	r.s.t.Helper()
+	return r.Do().ExpectStatus(status)
+}
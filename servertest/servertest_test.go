@@ -0,0 +1,70 @@
+package servertest_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/anyufly/gin_common/controllers"
+	"github.com/anyufly/gin_common/response"
+	"github.com/anyufly/gin_common/routers"
+	"github.com/anyufly/gin_common/server"
+	"github.com/anyufly/gin_common/servertest"
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewRunsLifecycleHooks(t *testing.T) {
+	var started, ready, stopped int
+	count := func(n *int) server.Hook {
+		return func(ctx context.Context) error {
+			*n++
+			return nil
+		}
+	}
+
+	t.Run("new", func(t *testing.T) {
+		servertest.New(t,
+			server.OnStart{Hook: count(&started)},
+			server.OnReady{Hook: count(&ready)},
+			server.OnShutdown{Hook: count(&stopped)},
+		)
+		if started != 1 || ready != 1 || stopped != 0 {
+			t.Errorf("started %d, ready %d, stopped %d before the cleanup", started, ready, stopped)
+		}
+	})
+	if started != 1 || ready != 1 || stopped != 1 {
+		t.Errorf("started %d, ready %d, stopped %d after the cleanup", started, ready, stopped)
+	}
+}
+
+func TestRequest(t *testing.T) {
+	echo := func(ctx *gin.Context) interface{} {
+		body, _ := io.ReadAll(ctx.Request.Body)
+		return response.SuccessWithData(map[string]interface{}{
+			"method": ctx.Request.Method,
+			"query":  ctx.Query("q"),
+			"header": ctx.GetHeader("X-Test"),
+			"type":   ctx.ContentType(),
+			"body":   string(body),
+		})
+	}
+	s := servertest.New(t, server.Routers{Routers: []routers.Router{servertest.Router{
+		Routes: map[string][]routers.RouteDesc{"/echo": {
+			{Method: http.MethodGet, Controller: []controllers.ControllerFunc{echo}},
+			{Method: http.MethodPut, Controller: []controllers.ControllerFunc{echo}},
+		}},
+	}}})
+
+	s.Get("/echo?a=1").WithQuery("q", "x").WithHeader("X-Test", "h").
+		ExpectStatus(http.StatusOK).
+		ExpectCode(response.SuccessCode).
+		ExpectJSONPath("data.method", http.MethodGet).
+		ExpectJSONPath("data.query", "x").
+		ExpectJSONPath("data.header", "h")
+	s.Put("/echo").WithJSON(map[string]int{"n": 1}).
+		ExpectStatus(http.StatusOK).
+		ExpectJSONPath("data.type", "application/json").
+		ExpectJSONPath("data.body", `{"n":1}`)
+	s.Get("/missing").ExpectStatus(http.StatusNotFound)
+}
@@ -15,6 +15,16 @@ import (
 	zhTrans "github.com/go-playground/validator/v10/translations/zh"
 )
 
+// PathParamTag is the translation key of the message answered when a path
+// parameter does not satisfy its routers constraint, {0} is the parameter and
+// {1} the constraint.
+const PathParamTag = "path_param"
+
+var pathParamTexts = map[string]string{
+	"zh": "{0}必须满足{1}",
+	"en": "{0} must match {1}",
+}
+
 type Validator interface {
 	FailedText(locale string) string
 	CallValidationEvenIfNull() bool
@@ -50,8 +60,15 @@ func initValidateTrans(locale string, v *validator.Validate) (err error) {
 	default:
 		err = enTrans.RegisterDefaultTranslations(v, trans.Trans())
 	}
+	if err != nil {
+		return
+	}
 
-	return
+	text, ok := pathParamTexts[locale]
+	if !ok {
+		text = pathParamTexts["en"]
+	}
+	return trans.Trans().Add(PathParamTag, text, true)
 }
 
 func registerFnWrapper(vl Validator, locale string) validator.RegisterTranslationsFunc {